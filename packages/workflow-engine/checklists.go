@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// checklistNumberPattern splits a numbered heading such as "1. REQUIREMENTS" or "1.2 Security"
+	checklistNumberPattern = regexp.MustCompile(`^(\d+(?:\.\d+)*)\.?\s+(.+)$`)
+	// checklistCategoryPattern matches a numbered bold category, e.g. "1. **Requirements Met:**"
+	checklistCategoryPattern = regexp.MustCompile(`^(\d+)\.\s+\*\*(.+?):?\*\*:?\s*$`)
+	// checklistItemPattern matches a checkbox item and its indentation
+	checklistItemPattern = regexp.MustCompile(`^(\s*)[-*] \[( |x|X|N/A|n/a)\]\s+(.*)$`)
+	// checklistLabelPattern splits "**Label:** criteria" items
+	checklistLabelPattern = regexp.MustCompile(`^\*\*(.+?):?\*\*:?\s*(.*)$`)
+	// severityMarkerPattern matches severity markers on items and headings
+	severityMarkerPattern = regexp.MustCompile(`(?i)\s*\[(blocker|critical|high|medium|low)\]`)
+	// slugPattern matches runs of characters not allowed in generated ids
+	slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// defaultSeverity is used for items with no severity marker on them or their headings
+const defaultSeverity = "medium"
+
+// checklistStatuses maps markdown checkbox states to item statuses
+var checklistStatuses = map[string]string{" ": "pending", "x": "pass", "X": "pass", "N/A": "n/a", "n/a": "n/a"}
+
+// markdownChecklistParser holds the numbering state while a markdown checklist is parsed
+type markdownChecklistParser struct {
+	checklist *Checklist
+	section   *ChecklistSection
+
+	sectionNumber    string
+	sectionSeverity  string
+	category         string
+	categoryID       string
+	categorySeverity string
+	categories       int
+
+	counts map[string]int
+	nested []nestedItem
+
+	last       string // kind of the last line parsed: section, category or item
+	itemIndent int
+	orphaned   []string // guidance of sections dropped for having no items
+}
+
+// nestedItem is an open checkbox item that indented items are numbered under
+type nestedItem struct {
+	indent int
+	id     string
+}
+
+// parseMarkdownChecklist parses a BMAD markdown checklist. Items get stable ids
+// from the heading numbering ("1.2.3" is the third item under "### 1.2"),
+// nested checkboxes are numbered under their parent, `### ` headings and
+// numbered bold labels become categories, `- [x]` and `- [N/A]` items keep
+// their status and [BLOCKER]/[HIGH]/[MEDIUM]/[LOW] markers set the severity.
+// [[LLM: ...]] blocks are kept as guidance on the checklist, section, category
+// or item they follow.
+func (cp *ChecklistProcessor) parseMarkdownChecklist(content string) error {
+	cp.checklist = Checklist{
+		Name:     "Parsed Markdown Checklist",
+		Version:  "1.0",
+		Sections: []ChecklistSection{},
+	}
+
+	p := &markdownChecklistParser{checklist: &cp.checklist, counts: map[string]int{}}
+	content, blocks := extractLLMBlocks(content)
+	for i, line := range strings.Split(content, "\n") {
+		p.parseLine(line)
+		for len(blocks) > 0 && blocks[0].line == i {
+			p.addGuidance(blocks[0])
+			blocks = blocks[1:]
+		}
+	}
+	p.closeSection()
+	for _, guidance := range p.orphaned {
+		p.checklist.Guidance = joinGuidance(p.checklist.Guidance, guidance)
+	}
+	return nil
+}
+
+func (p *markdownChecklistParser) parseLine(line string) {
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case strings.HasPrefix(trimmed, "# "):
+		if p.checklist.Name == "Parsed Markdown Checklist" {
+			p.checklist.Name = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+		}
+	case strings.HasPrefix(trimmed, "## "):
+		p.openSection(strings.TrimPrefix(trimmed, "## "))
+	case strings.HasPrefix(trimmed, "### ") && p.section != nil:
+		number, title := splitChecklistNumber(strings.TrimPrefix(trimmed, "### "))
+		p.openCategory(number, title)
+	case checklistCategoryPattern.MatchString(trimmed) && p.section != nil:
+		match := checklistCategoryPattern.FindStringSubmatch(trimmed)
+		number := match[1]
+		if p.sectionNumber != "" {
+			number = p.sectionNumber + "." + number
+		}
+		p.openCategory(number, match[2])
+	case checklistItemPattern.MatchString(line) && p.section != nil:
+		match := checklistItemPattern.FindStringSubmatch(line)
+		p.addItem(len(match[1]), checklistStatuses[match[2]], match[3])
+	}
+}
+
+// openSection starts a `## ` section; unnumbered sections get an id from their title
+func (p *markdownChecklistParser) openSection(heading string) {
+	p.closeSection()
+
+	heading, severity := extractSeverity(heading)
+	number, title := splitChecklistNumber(heading)
+	id := number
+	if id == "" {
+		id = strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	}
+
+	p.section = &ChecklistSection{ID: id, Title: title, Items: []ChecklistItem{}}
+	p.sectionNumber = number
+	p.sectionSeverity = severity
+	p.category, p.categoryID, p.categorySeverity = "", "", ""
+	p.categories = 0
+	p.nested = nil
+	p.last = "section"
+}
+
+// openCategory starts a category within the current section
+func (p *markdownChecklistParser) openCategory(number, title string) {
+	title, severity := extractSeverity(title)
+	p.categories++
+	if number == "" {
+		number = p.section.ID + "." + strconv.Itoa(p.categories)
+	}
+
+	p.category = title
+	p.categoryID = number
+	p.categorySeverity = severity
+	p.nested = nil
+	p.last = "category"
+}
+
+// addItem adds a checkbox item, numbered under its parent item, category or section
+func (p *markdownChecklistParser) addItem(indent int, status, text string) {
+	for len(p.nested) > 0 && p.nested[len(p.nested)-1].indent >= indent {
+		p.nested = p.nested[:len(p.nested)-1]
+	}
+
+	prefix := p.section.ID
+	switch {
+	case len(p.nested) > 0:
+		prefix = p.nested[len(p.nested)-1].id
+	case p.categoryID != "":
+		prefix = p.categoryID
+	}
+	p.counts[prefix]++
+	id := prefix + "." + strconv.Itoa(p.counts[prefix])
+	p.nested = append(p.nested, nestedItem{indent: indent, id: id})
+
+	text, severity := extractSeverity(text)
+	if severity == "" {
+		severity = p.categorySeverity
+	}
+	if severity == "" {
+		severity = p.sectionSeverity
+	}
+	if severity == "" {
+		severity = defaultSeverity
+	}
+
+	item := ChecklistItem{ID: id, Category: p.category, Text: text, Severity: severity, Status: status}
+	if item.Category == "" {
+		item.Category = p.section.Title
+	}
+	if match := checklistLabelPattern.FindStringSubmatch(text); match != nil {
+		item.Text = match[1]
+		item.Criteria = match[2]
+	}
+	p.section.Items = append(p.section.Items, item)
+	p.last = "item"
+	p.itemIndent = indent
+}
+
+// addGuidance attaches an [[LLM: ...]] block to what it follows: a block
+// inline with or indented under an item belongs to the item, one after a
+// heading to its section or category, and anything else (the opening
+// instructions and final report blocks) to the checklist
+func (p *markdownChecklistParser) addGuidance(block llmBlock) {
+	switch {
+	case p.section == nil:
+		p.checklist.Guidance = joinGuidance(p.checklist.Guidance, block.text)
+	case p.last == "item" && (block.inline || block.indent > p.itemIndent):
+		item := &p.section.Items[len(p.section.Items)-1]
+		item.Guidance = joinGuidance(item.Guidance, block.text)
+	case p.last == "category":
+		if p.section.CategoryGuidance == nil {
+			p.section.CategoryGuidance = map[string]string{}
+		}
+		p.section.CategoryGuidance[p.category] = joinGuidance(p.section.CategoryGuidance[p.category], block.text)
+	case p.last == "section":
+		p.section.Guidance = joinGuidance(p.section.Guidance, block.text)
+	default:
+		p.checklist.Guidance = joinGuidance(p.checklist.Guidance, block.text)
+	}
+}
+
+// closeSection adds the current section to the checklist if it has items
+func (p *markdownChecklistParser) closeSection() {
+	if p.section != nil && len(p.section.Items) > 0 {
+		p.checklist.Sections = append(p.checklist.Sections, *p.section)
+	} else if p.section != nil && p.section.Guidance != "" {
+		p.orphaned = append(p.orphaned, p.section.Guidance)
+	}
+	p.section = nil
+}
+
+// joinGuidance appends a guidance block, separated by a blank line
+func joinGuidance(guidance, block string) string {
+	switch {
+	case block == "":
+		return guidance
+	case guidance == "":
+		return block
+	}
+	return guidance + "\n\n" + block
+}
+
+// guidanceFor returns the guidance that applies to a section: the checklist's,
+// the section's and that of its categories, in order. It is sent as system
+// guidance when the section is evaluated by an agent.
+func (cp *ChecklistProcessor) guidanceFor(section ChecklistSection) string {
+	guidance := joinGuidance(cp.checklist.Guidance, section.Guidance)
+	seen := map[string]bool{}
+	for _, item := range section.Items {
+		if text, ok := section.CategoryGuidance[item.Category]; ok && !seen[item.Category] {
+			seen[item.Category] = true
+			guidance = joinGuidance(guidance, fmt.Sprintf("%s:\n%s", item.Category, text))
+		}
+	}
+	return guidance
+}
+
+// showGuidance prints a guidance block for the person validating the checklist
+func showGuidance(label, guidance string) {
+	if guidance == "" {
+		return
+	}
+	fmt.Printf("   💡 %s:\n", label)
+	for _, line := range strings.Split(guidance, "\n") {
+		fmt.Printf("   │ %s\n", line)
+	}
+}
+
+// checkedInSource reports whether an item was already checked (or marked not
+// applicable) in the checklist file, so validation keeps its status
+func checkedInSource(item ChecklistItem) bool {
+	return item.Status == "pass" || item.Status == "n/a"
+}
+
+// resultsWithStatus returns the results with a status in checklist order
+func (cp *ChecklistProcessor) resultsWithStatus(status string) []ChecklistItem {
+	var items []ChecklistItem
+	for _, section := range cp.checklist.Sections {
+		for _, item := range section.Items {
+			if result, ok := cp.results[item.ID]; ok && result.Status == status {
+				items = append(items, result)
+			}
+		}
+	}
+	return items
+}
+
+// splitChecklistNumber splits "1.2 Title" into its number and title
+func splitChecklistNumber(heading string) (string, string) {
+	heading = strings.TrimSpace(heading)
+	if match := checklistNumberPattern.FindStringSubmatch(heading); match != nil {
+		return match[1], strings.TrimSpace(match[2])
+	}
+	return "", heading
+}
+
+// extractSeverity removes a severity marker from text and returns the severity;
+// [CRITICAL] is treated as a blocker
+func extractSeverity(text string) (string, string) {
+	match := severityMarkerPattern.FindStringSubmatch(text)
+	if match == nil {
+		return strings.TrimSpace(text), ""
+	}
+
+	severity := strings.ToLower(match[1])
+	if severity == "critical" {
+		severity = "blocker"
+	}
+	return strings.TrimSpace(severityMarkerPattern.ReplaceAllString(text, "")), severity
+}
+
+// llmBlock is an [[LLM: ...]] guidance block found in a checklist
+type llmBlock struct {
+	line   int    // line the block starts on
+	indent int    // indentation of that line
+	inline bool   // the block follows other text on its line
+	text   string // the block's instructions
+}
+
+// extractLLMBlocks removes [[LLM: ...]] guidance blocks from content, keeping
+// line breaks so the remaining lines keep their positions, and returns the
+// blocks with the line each starts on. Blocks may contain nested [[...]]
+// markers such as [[FRONTEND ONLY]].
+func extractLLMBlocks(content string) (string, []llmBlock) {
+	var out, block strings.Builder
+	var blocks []llmBlock
+	depth, line, lineStart := 0, 0, 0
+
+	for i := 0; i < len(content); i++ {
+		switch {
+		case depth == 0 && strings.HasPrefix(content[i:], "[[LLM:"):
+			prefix := content[lineStart:i]
+			blocks = append(blocks, llmBlock{
+				line:   line,
+				indent: len(prefix) - len(strings.TrimLeft(prefix, " \t")),
+				inline: strings.TrimSpace(prefix) != "",
+			})
+			block.Reset()
+			depth = 1
+			i += len("[[LLM:") - 1
+		case depth > 0 && strings.HasPrefix(content[i:], "[["):
+			depth++
+			block.WriteString("[[")
+			i++
+		case depth > 0 && strings.HasPrefix(content[i:], "]]"):
+			depth--
+			if depth == 0 {
+				blocks[len(blocks)-1].text = strings.TrimSpace(block.String())
+			} else {
+				block.WriteString("]]")
+			}
+			i++
+		case depth > 0:
+			block.WriteByte(content[i])
+			if content[i] == '\n' {
+				out.WriteByte('\n')
+				line++
+				lineStart = i + 1
+			}
+		default:
+			out.WriteByte(content[i])
+			if content[i] == '\n' {
+				line++
+				lineStart = i + 1
+			}
+		}
+	}
+	return out.String(), blocks
+}
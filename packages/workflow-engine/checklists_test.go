@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func loadTestChecklist(t *testing.T, name string) *ChecklistProcessor {
+	t.Helper()
+
+	cp := &ChecklistProcessor{}
+	if err := cp.loadChecklist(filepath.Join("..", "..", "bmad-core", "checklists", name)); err != nil {
+		t.Fatalf("Failed to load %s: %v", name, err)
+	}
+	return cp
+}
+
+func readTestChecklist(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "bmad-core", "checklists", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// allGuidance joins every guidance block attached to a checklist
+func allGuidance(checklist Checklist) string {
+	all := []string{checklist.Guidance}
+	for _, section := range checklist.Sections {
+		all = append(all, section.Guidance)
+		for _, guidance := range section.CategoryGuidance {
+			all = append(all, guidance)
+		}
+		for _, item := range section.Items {
+			all = append(all, item.Guidance)
+		}
+	}
+	return strings.Join(all, "\n")
+}
+
+func checklistItems(checklist Checklist) map[string]ChecklistItem {
+	items := map[string]ChecklistItem{}
+	for _, section := range checklist.Sections {
+		for _, item := range section.Items {
+			items[item.ID] = item
+		}
+	}
+	return items
+}
+
+func TestParseMarkdownChecklist_BMADChecklists(t *testing.T) {
+	tests := []struct {
+		file     string
+		name     string
+		items    int
+		id       string
+		category string
+		text     string
+	}{
+		{"architect-checklist.md", "Architect Solution Validation Checklist", 195, "1.2.3", "Non-Functional Requirements Alignment", "Security requirements have corresponding technical controls"},
+		{"change-checklist.md", "Change Navigation Checklist", 63, "1.2.1", "Understand the Trigger & Context", "Is it a technical limitation/dead-end?"},
+		{"pm-checklist.md", "Product Manager (PM) Requirements Checklist", 139, "1.1.1", "Problem Statement", "Clear articulation of the problem being solved"},
+		{"po-master-checklist.md", "Product Owner (PO) Master Validation Checklist", 151, "1.4.5", "Core Dependencies", "[[BROWNFIELD ONLY]] Version compatibility with existing stack verified"},
+		{"story-dod-checklist.md", "Story Definition of Done (DoD) Checklist", 28, "3.3", "Testing", "All tests (unit, integration, E2E if applicable) pass successfully."},
+		{"story-draft-checklist.md", "Story Draft Checklist", 23, "2.1", "TECHNICAL IMPLEMENTATION GUIDANCE", "Key files to create/modify are identified (not necessarily exhaustive)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cp := loadTestChecklist(t, tt.file)
+			if cp.checklist.Name != tt.name {
+				t.Errorf("Expected name %q, got %q", tt.name, cp.checklist.Name)
+			}
+
+			total := 0
+			for _, section := range cp.checklist.Sections {
+				total += len(section.Items)
+			}
+			items := checklistItems(cp.checklist)
+			if total != tt.items || len(items) != tt.items {
+				t.Errorf("Expected %d items with unique ids, got %d items and %d ids", tt.items, total, len(items))
+			}
+
+			item, ok := items[tt.id]
+			if !ok {
+				t.Fatalf("Expected an item %s", tt.id)
+			}
+			if item.Category != tt.category || item.Text != tt.text || item.Severity != defaultSeverity || item.Status != "pending" {
+				t.Errorf("Unexpected item %s: %+v", tt.id, item)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownChecklist_PMChecklist(t *testing.T) {
+	cp := loadTestChecklist(t, "pm-checklist.md")
+
+	// Categories of each section, in order
+	want := []struct {
+		title      string
+		categories []string
+	}{
+		{"PROBLEM DEFINITION & CONTEXT", []string{"Problem Statement", "Business Goals & Success Metrics", "User Research & Insights"}},
+		{"MVP SCOPE DEFINITION", []string{"Core Functionality", "Scope Boundaries", "MVP Validation Approach"}},
+		{"USER EXPERIENCE REQUIREMENTS", []string{"User Journeys & Flows", "Usability Requirements", "UI Requirements"}},
+		{"FUNCTIONAL REQUIREMENTS", []string{"Feature Completeness", "Requirements Quality", "User Stories & Acceptance Criteria"}},
+		{"NON-FUNCTIONAL REQUIREMENTS", []string{"Performance Requirements", "Security & Compliance", "Reliability & Resilience", "Technical Constraints"}},
+		{"EPIC & STORY STRUCTURE", []string{"Epic Definition", "Story Breakdown", "First Epic Completeness"}},
+		{"TECHNICAL GUIDANCE", []string{"Architecture Guidance", "Technical Decision Framework", "Implementation Considerations"}},
+		{"CROSS-FUNCTIONAL REQUIREMENTS", []string{"Data Requirements", "Integration Requirements", "Operational Requirements"}},
+		{"CLARITY & COMMUNICATION", []string{"Documentation Quality", "Stakeholder Alignment"}},
+	}
+
+	if len(cp.checklist.Sections) != len(want) {
+		t.Fatalf("Expected %d sections, got %d", len(want), len(cp.checklist.Sections))
+	}
+
+	for i, section := range cp.checklist.Sections {
+		expected := want[i]
+		if section.ID != strconv.Itoa(i+1) || section.Title != expected.title {
+			t.Errorf("Section %d: expected %d %q, got %s %q", i+1, i+1, expected.title, section.ID, section.Title)
+			continue
+		}
+
+		// Items are numbered <section>.<category>.<item> within each category
+		category, number := 0, 0
+		for _, item := range section.Items {
+			if category == 0 || item.Category != expected.categories[category-1] {
+				category, number = category+1, 0
+			}
+			number++
+			if category > len(expected.categories) {
+				t.Fatalf("Item %s: unexpected category %q", item.ID, item.Category)
+			}
+
+			name := expected.categories[category-1]
+			id := fmt.Sprintf("%d.%d.%d", i+1, category, number)
+			if item.ID != id || item.Category != name || item.Severity != defaultSeverity || item.Status != "pending" {
+				t.Errorf("Expected item %s in %q, got %+v", id, name, item)
+			}
+		}
+		if category != len(expected.categories) {
+			t.Errorf("Section %s: expected %d categories, got %d", section.ID, len(expected.categories), category)
+		}
+	}
+}
+
+func TestParseMarkdownChecklist_StatusSeverityAndLabels(t *testing.T) {
+	content := `# Release Checklist
+
+[[LLM: Check each item. Skip [[MOBILE ONLY]] sections.
+- [ ] not an item]]
+
+## 1. SECURITY [HIGH]
+
+### 1.1 Secrets
+
+- [x] **Secrets Stored:** No secrets in the repository
+- [ ] [BLOCKER] Credentials are rotated
+  - [N/A] Hardware keys are issued
+
+## Wrap Up
+
+- [ ] Changelog updated [low]
+`
+
+	cp := &ChecklistProcessor{}
+	if err := cp.parseMarkdownChecklist(content); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []ChecklistItem{
+		{ID: "1.1.1", Category: "Secrets", Text: "Secrets Stored", Criteria: "No secrets in the repository", Severity: "high", Status: "pass"},
+		{ID: "1.1.2", Category: "Secrets", Text: "Credentials are rotated", Severity: "blocker", Status: "pending"},
+		{ID: "1.1.2.1", Category: "Secrets", Text: "Hardware keys are issued", Severity: "high", Status: "n/a"},
+		{ID: "wrap-up.1", Category: "Wrap Up", Text: "Changelog updated", Severity: "low", Status: "pending"},
+	}
+
+	items := checklistItems(cp.checklist)
+	if len(items) != len(want) {
+		t.Fatalf("Expected %d items, got %+v", len(want), cp.checklist.Sections)
+	}
+	for _, expected := range want {
+		if got := items[expected.ID]; got != expected {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+	}
+	if cp.checklist.Sections[0].Title != "SECURITY" {
+		t.Errorf("Expected the severity marker removed from the title, got %q", cp.checklist.Sections[0].Title)
+	}
+}
+
+func TestParseMarkdownChecklist_Guidance(t *testing.T) {
+	cp := loadTestChecklist(t, "architect-checklist.md")
+	checklist := cp.checklist
+
+	if !strings.HasPrefix(checklist.Guidance, "INITIALIZATION INSTRUCTIONS") || !strings.Contains(checklist.Guidance, "FINAL VALIDATION REPORT GENERATION") {
+		t.Errorf("Expected the opening and final report blocks on the checklist, got:\n%s", checklist.Guidance)
+	}
+	if !strings.HasPrefix(checklist.Sections[0].Guidance, "Before evaluating this section") {
+		t.Errorf("Expected section 1 guidance, got %q", checklist.Sections[0].Guidance)
+	}
+	if got := checklist.Sections[2].CategoryGuidance["Frontend Architecture [[FRONTEND ONLY]]"]; !strings.HasPrefix(got, "Skip this entire section if this is a backend-only") {
+		t.Errorf("Expected category 3.2 guidance, got %q", got)
+	}
+
+	_, found := extractLLMBlocks(readTestChecklist(t, "architect-checklist.md"))
+	if len(found) != 14 {
+		t.Errorf("Expected 14 guidance blocks, got %d", len(found))
+	}
+	for _, block := range found {
+		if !strings.Contains(allGuidance(checklist), block.text) {
+			t.Errorf("Guidance block not attached: %.60q", block.text)
+		}
+	}
+
+	dod := loadTestChecklist(t, "story-dod-checklist.md")
+	if got := dod.checklist.Sections[0].CategoryGuidance["Requirements Met"]; got != "Be specific - list each requirement and whether it's complete" {
+		t.Errorf("Expected DoD category guidance, got %q", got)
+	}
+}
+
+func TestParseMarkdownChecklist_ItemGuidance(t *testing.T) {
+	content := `## Release
+
+- [ ] Tag pushed [[LLM: Check the tag matches the version]]
+- [ ] Notes written
+  [[LLM: Notes must list breaking changes]]
+
+[[LLM: Summarize the release]]
+`
+
+	cp := &ChecklistProcessor{}
+	if err := cp.parseMarkdownChecklist(content); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	items := cp.checklist.Sections[0].Items
+	if items[0].Text != "Tag pushed" || items[0].Guidance != "Check the tag matches the version" {
+		t.Errorf("Unexpected inline guidance: %+v", items[0])
+	}
+	if items[1].Guidance != "Notes must list breaking changes" {
+		t.Errorf("Unexpected indented guidance: %+v", items[1])
+	}
+	if cp.checklist.Guidance != "Summarize the release" {
+		t.Errorf("Expected the trailing block on the checklist, got %q", cp.checklist.Guidance)
+	}
+	if got := cp.guidanceFor(cp.checklist.Sections[0]); got != "Summarize the release" {
+		t.Errorf("Unexpected section guidance %q", got)
+	}
+}
+
+func TestExtractLLMBlocks_NestedMarkers(t *testing.T) {
+	content := "before [[LLM: skip [[FRONTEND ONLY]] sections\nsecond line]] after\n### 3.2 UI [[FRONTEND ONLY]]"
+
+	got, blocks := extractLLMBlocks(content)
+	want := "before \n after\n### 3.2 UI [[FRONTEND ONLY]]"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if strings.Count(got, "\n") != strings.Count(content, "\n") {
+		t.Error("Expected line breaks inside blocks to be kept")
+	}
+	if len(blocks) != 1 || blocks[0].text != "skip [[FRONTEND ONLY]] sections\nsecond line" || blocks[0].line != 0 || !blocks[0].inline {
+		t.Errorf("Unexpected blocks: %+v", blocks)
+	}
+}
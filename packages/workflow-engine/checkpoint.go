@@ -0,0 +1,395 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	checkpointVersion  = 1
+	checkpointFilename = "state.json"
+	defaultRunsDir     = ".bmad/runs"
+)
+
+// RunState is the persisted state of a workflow run, written after every step
+type RunState struct {
+	Version      int                               `json:"version"`
+	WorkflowFile string                            `json:"workflow_file"`
+	WorkflowHash string                            `json:"workflow_hash"`
+	CreatedAt    time.Time                         `json:"created_at"`
+	UpdatedAt    time.Time                         `json:"updated_at"`
+	Variables    map[string]interface{}            `json:"variables,omitempty"`
+	Answers      map[string]map[string]interface{} `json:"answers,omitempty"` // values entered in unfinished steps, by step id
+	Steps        []StepCheckpoint                  `json:"steps"`
+	Checksum     string                            `json:"checksum"`
+}
+
+// StepCheckpoint is the serializable form of a StepResult
+type StepCheckpoint struct {
+	StepIndex  int                 `json:"step_index"`
+	ID         string              `json:"id"`
+	Success    bool                `json:"success"`
+	Skipped    bool                `json:"skipped,omitempty"`
+	SkipReason string              `json:"skip_reason,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	ErrorClass ErrorClass          `json:"error_class,omitempty"`
+	OutputKind string              `json:"output_kind,omitempty"`
+	Output     json.RawMessage     `json:"output,omitempty"`
+	StartTime  time.Time           `json:"start_time"`
+	EndTime    time.Time           `json:"end_time"`
+	Duration   time.Duration       `json:"duration"`
+	Attempts   []AttemptCheckpoint `json:"attempts,omitempty"`
+}
+
+// AttemptCheckpoint is the serializable form of a StepAttempt
+type AttemptCheckpoint struct {
+	Number     int           `json:"number"`
+	Error      string        `json:"error,omitempty"`
+	ErrorClass ErrorClass    `json:"error_class,omitempty"`
+	StartTime  time.Time     `json:"start_time"`
+	Duration   time.Duration `json:"duration"`
+	Delay      time.Duration `json:"delay,omitempty"`
+}
+
+// Checkpointer persists run state into a run directory
+type Checkpointer struct {
+	runDir string
+	steps  []WorkflowStep
+	state  *RunState
+	mutex  sync.Mutex
+}
+
+// NewCheckpointer starts a fresh run directory for a workflow file
+func NewCheckpointer(runDir, workflowFile string, steps []WorkflowStep) (*Checkpointer, error) {
+	hash, err := hashFile(workflowFile)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing workflow file: %v", err)
+	}
+
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating run directory: %v", err)
+	}
+
+	now := time.Now()
+	c := &Checkpointer{
+		runDir: runDir,
+		steps:  steps,
+		state: &RunState{
+			Version:      checkpointVersion,
+			WorkflowFile: workflowFile,
+			WorkflowHash: hash,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Steps:        []StepCheckpoint{},
+		},
+	}
+
+	return c, c.save()
+}
+
+// ResumeCheckpointer reopens an existing run directory and verifies that its
+// checkpoint is intact and still matches the workflow file on disk
+func ResumeCheckpointer(runDir string, steps []WorkflowStep, state *RunState) (*Checkpointer, error) {
+	hash, err := hashFile(state.WorkflowFile)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing workflow file: %v", err)
+	}
+
+	if hash != state.WorkflowHash {
+		return nil, fmt.Errorf("workflow file %s has changed since the checkpoint was written", state.WorkflowFile)
+	}
+
+	for _, step := range state.Steps {
+		if step.StepIndex < 0 || step.StepIndex >= len(steps) || stepID(steps[step.StepIndex], step.StepIndex) != step.ID {
+			return nil, fmt.Errorf("checkpoint step %d (%s) does not match the workflow", step.StepIndex+1, step.ID)
+		}
+	}
+
+	return &Checkpointer{runDir: runDir, steps: steps, state: state}, nil
+}
+
+// LoadRunState reads and verifies the checkpoint stored in a run directory
+func LoadRunState(runDir string) (*RunState, error) {
+	data, err := ioutil.ReadFile(filepath.Join(runDir, checkpointFilename))
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %v", err)
+	}
+
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("corrupt checkpoint: %v", err)
+	}
+
+	if state.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", state.Version)
+	}
+
+	expected, err := state.computeChecksum()
+	if err != nil {
+		return nil, err
+	}
+	if expected != state.Checksum {
+		return nil, fmt.Errorf("corrupt checkpoint: checksum mismatch")
+	}
+
+	return &state, nil
+}
+
+// WorkflowFile returns the workflow file the run belongs to
+func (c *Checkpointer) WorkflowFile() string {
+	return c.state.WorkflowFile
+}
+
+// RunDir returns the directory the checkpoint is written to
+func (c *Checkpointer) RunDir() string {
+	return c.runDir
+}
+
+// Record stores a step result and the collected variables, then saves the checkpoint
+func (c *Checkpointer) Record(result *StepResult, variables map[string]interface{}) error {
+	checkpoint := StepCheckpoint{
+		StepIndex:  result.StepIndex,
+		ID:         stepID(c.steps[result.StepIndex], result.StepIndex),
+		Success:    result.Success,
+		Skipped:    result.Skipped,
+		SkipReason: result.SkipReason,
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		Duration:   result.Duration,
+	}
+	if result.Error != nil {
+		checkpoint.Error = result.Error.Error()
+		checkpoint.ErrorClass = ErrorClassOf(result.Error)
+	}
+	for _, attempt := range result.Attempts {
+		record := AttemptCheckpoint{
+			Number:     attempt.Number,
+			ErrorClass: attempt.ErrorClass,
+			StartTime:  attempt.StartTime,
+			Duration:   attempt.Duration,
+			Delay:      attempt.Delay,
+		}
+		if attempt.Error != nil {
+			record.Error = attempt.Error.Error()
+		}
+		checkpoint.Attempts = append(checkpoint.Attempts, record)
+	}
+
+	kind, raw, err := encodeStepOutput(result.Output)
+	if err != nil {
+		return fmt.Errorf("error encoding output of step %d: %v", result.StepIndex+1, err)
+	}
+	checkpoint.OutputKind = kind
+	checkpoint.Output = raw
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	replaced := false
+	for i, existing := range c.state.Steps {
+		if existing.StepIndex == checkpoint.StepIndex {
+			c.state.Steps[i] = checkpoint
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		c.state.Steps = append(c.state.Steps, checkpoint)
+	}
+	sort.Slice(c.state.Steps, func(i, j int) bool {
+		return c.state.Steps[i].StepIndex < c.state.Steps[j].StepIndex
+	})
+
+	if variables != nil {
+		c.state.Variables = make(map[string]interface{}, len(variables))
+		for k, v := range variables {
+			c.state.Variables[k] = v
+		}
+	}
+	// A finished step is restored on resume and never asks again
+	if result.Success {
+		delete(c.state.Answers, checkpoint.ID)
+	}
+
+	return c.save()
+}
+
+// RecordAnswers adds the values entered in a running step to those kept from
+// earlier attempts and saves the checkpoint, so a resumed run does not ask for
+// them again
+func (c *Checkpointer) RecordAnswers(step string, answers map[string]interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state.Answers == nil {
+		c.state.Answers = make(map[string]map[string]interface{})
+	}
+	if c.state.Answers[step] == nil {
+		c.state.Answers[step] = make(map[string]interface{}, len(answers))
+	}
+	for k, v := range answers {
+		c.state.Answers[step][k] = v
+	}
+
+	return c.save()
+}
+
+// save writes the checkpoint atomically; callers must hold the mutex or own c exclusively
+func (c *Checkpointer) save() error {
+	c.state.UpdatedAt = time.Now()
+
+	checksum, err := c.state.computeChecksum()
+	if err != nil {
+		return err
+	}
+	c.state.Checksum = checksum
+
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoint: %v", err)
+	}
+
+	path := filepath.Join(c.runDir, checkpointFilename)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Results converts the checkpointed steps back into step results
+func (s *RunState) Results() (map[int]*StepResult, error) {
+	results := make(map[int]*StepResult)
+
+	for _, step := range s.Steps {
+		output, err := decodeStepOutput(step.OutputKind, step.Output)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt checkpoint output for step %d: %v", step.StepIndex+1, err)
+		}
+
+		result := &StepResult{
+			StepIndex:  step.StepIndex,
+			Success:    step.Success,
+			Skipped:    step.Skipped,
+			SkipReason: step.SkipReason,
+			Output:     output,
+			StartTime:  step.StartTime,
+			EndTime:    step.EndTime,
+			Duration:   step.Duration,
+		}
+		if step.Error != "" {
+			result.Error = NewStepError(step.ErrorClass, nil, "%s", step.Error)
+		}
+		for _, attempt := range step.Attempts {
+			restored := StepAttempt{
+				Number:     attempt.Number,
+				ErrorClass: attempt.ErrorClass,
+				StartTime:  attempt.StartTime,
+				Duration:   attempt.Duration,
+				Delay:      attempt.Delay,
+			}
+			if attempt.Error != "" {
+				restored.Error = NewStepError(attempt.ErrorClass, nil, "%s", attempt.Error)
+			}
+			result.Attempts = append(result.Attempts, restored)
+		}
+
+		results[step.StepIndex] = result
+	}
+
+	return results, nil
+}
+
+// computeChecksum hashes the state with its checksum field cleared
+func (s *RunState) computeChecksum() (string, error) {
+	clone := *s
+	clone.Checksum = ""
+
+	data, err := json.Marshal(clone)
+	if err != nil {
+		return "", fmt.Errorf("error encoding checkpoint: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// encodeStepOutput serializes a step output together with a kind tag for decoding
+func encodeStepOutput(output interface{}) (string, json.RawMessage, error) {
+	if output == nil {
+		return "", nil, nil
+	}
+
+	var kind string
+	switch output.(type) {
+	case *OpenCodeResult:
+		kind = "opencode"
+	case *TemplateStepOutput:
+		kind = "template"
+	case *ChecklistStepOutput:
+		kind = "checklist"
+	default:
+		kind = "json"
+	}
+
+	raw, err := json.Marshal(output)
+	return kind, raw, err
+}
+
+// decodeStepOutput restores a step output serialized by encodeStepOutput
+func decodeStepOutput(kind string, raw json.RawMessage) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var target interface{}
+	switch kind {
+	case "opencode":
+		target = &OpenCodeResult{}
+	case "template":
+		target = &TemplateStepOutput{}
+	case "checklist":
+		target = &ChecklistStepOutput{}
+	default:
+		var generic interface{}
+		err := json.Unmarshal(raw, &generic)
+		return generic, err
+	}
+
+	err := json.Unmarshal(raw, target)
+	return target, err
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file's contents
+func hashFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var runDirUnsafeChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newRunDir returns a fresh run directory path for a workflow under root
+func newRunDir(root, workflowName string, now time.Time) string {
+	slug := strings.Trim(runDirUnsafeChars.ReplaceAllString(strings.ToLower(workflowName), "-"), "-")
+	if slug == "" {
+		slug = "workflow"
+	}
+
+	return filepath.Join(root, fmt.Sprintf("%s-%s", slug, now.Format("20060102-150405")))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeTestWorkflow writes a workflow file into dir and returns its path
+func writeTestWorkflow(t *testing.T, dir, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "workflow.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+	return path
+}
+
+func TestCheckpoint_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	workflowFile := writeTestWorkflow(t, dir, "name: test\nsteps:\n  - id: design\n  - id: build\n")
+	steps := []WorkflowStep{{ID: "design"}, {ID: "build"}}
+	runDir := filepath.Join(dir, "run")
+
+	checkpointer, err := NewCheckpointer(runDir, workflowFile, steps)
+	if err != nil {
+		t.Fatalf("Failed to create checkpointer: %v", err)
+	}
+
+	result := &StepResult{
+		StepIndex: 0,
+		Success:   true,
+		Output: &TemplateStepOutput{
+			File:     "docs/architecture.md",
+			Document: []string{"# Architecture", "", "## Overview"},
+		},
+		StartTime: time.Now().Add(-time.Second),
+		EndTime:   time.Now(),
+		Duration:  time.Second,
+	}
+	variables := map[string]interface{}{"project_name": "Atlas"}
+
+	// Answers of a finished step are dropped, those of an unfinished step kept
+	if err := checkpointer.RecordAnswers("design", map[string]interface{}{"style": "REST"}); err != nil {
+		t.Fatalf("Failed to record answers: %v", err)
+	}
+	if err := checkpointer.RecordAnswers("build", map[string]interface{}{"status": "Approved"}); err != nil {
+		t.Fatalf("Failed to record answers: %v", err)
+	}
+	if err := checkpointer.RecordAnswers("build", map[string]interface{}{"owner": "Ada"}); err != nil {
+		t.Fatalf("Failed to record answers: %v", err)
+	}
+	if err := checkpointer.Record(result, variables); err != nil {
+		t.Fatalf("Failed to record step: %v", err)
+	}
+
+	failed := &StepResult{StepIndex: 1, Success: false, Error: fmt.Errorf("agent crashed")}
+	if err := checkpointer.Record(failed, variables); err != nil {
+		t.Fatalf("Failed to record step: %v", err)
+	}
+
+	state, err := LoadRunState(runDir)
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	if state.Variables["project_name"] != "Atlas" {
+		t.Errorf("Variables not restored, got %v", state.Variables)
+	}
+	if len(state.Answers) != 1 || state.Answers["build"]["status"] != "Approved" || state.Answers["build"]["owner"] != "Ada" {
+		t.Errorf("Expected only the unfinished step's answers, got %v", state.Answers)
+	}
+
+	results, err := state.Results()
+	if err != nil {
+		t.Fatalf("Failed to decode results: %v", err)
+	}
+
+	output, ok := results[0].Output.(*TemplateStepOutput)
+	if !ok {
+		t.Fatalf("Expected *TemplateStepOutput, got %T", results[0].Output)
+	}
+
+	if output.File != "docs/architecture.md" || len(output.Document) != 3 {
+		t.Errorf("Template output not restored: %+v", output)
+	}
+
+	if results[1].Success || results[1].Error == nil || results[1].Error.Error() != "agent crashed" {
+		t.Errorf("Failed step not restored correctly: %+v", results[1])
+	}
+
+	if _, err := ResumeCheckpointer(runDir, steps, state); err != nil {
+		t.Errorf("Expected checkpoint to match workflow, got %v", err)
+	}
+}
+
+func TestLoadRunState_DetectsCorruption(t *testing.T) {
+	dir := t.TempDir()
+	workflowFile := writeTestWorkflow(t, dir, "name: test\nsteps:\n  - id: design\n")
+	runDir := filepath.Join(dir, "run")
+
+	checkpointer, err := NewCheckpointer(runDir, workflowFile, []WorkflowStep{{ID: "design"}})
+	if err != nil {
+		t.Fatalf("Failed to create checkpointer: %v", err)
+	}
+	if err := checkpointer.Record(&StepResult{StepIndex: 0, Success: true}, nil); err != nil {
+		t.Fatalf("Failed to record step: %v", err)
+	}
+
+	statePath := filepath.Join(runDir, checkpointFilename)
+	data, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("Failed to read checkpoint: %v", err)
+	}
+
+	tampered := strings.Replace(string(data), `"success": true`, `"success": false`, 1)
+	if err := ioutil.WriteFile(statePath, []byte(tampered), 0644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	if _, err := LoadRunState(runDir); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected checksum mismatch, got %v", err)
+	}
+
+	if err := ioutil.WriteFile(statePath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	if _, err := LoadRunState(runDir); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("Expected corrupt checkpoint error, got %v", err)
+	}
+}
+
+func TestResumeCheckpointer_WorkflowChanged(t *testing.T) {
+	dir := t.TempDir()
+	workflowFile := writeTestWorkflow(t, dir, "name: test\nsteps:\n  - id: design\n")
+	runDir := filepath.Join(dir, "run")
+	steps := []WorkflowStep{{ID: "design"}}
+
+	if _, err := NewCheckpointer(runDir, workflowFile, steps); err != nil {
+		t.Fatalf("Failed to create checkpointer: %v", err)
+	}
+
+	writeTestWorkflow(t, dir, "name: test\nsteps:\n  - id: design\n  - id: build\n")
+
+	state, err := LoadRunState(runDir)
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	if _, err := ResumeCheckpointer(runDir, steps, state); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("Expected workflow changed error, got %v", err)
+	}
+}
+
+func TestExecuteParallel_SkipsRestoredSteps(t *testing.T) {
+	for _, enableParallel := range []bool{true, false} {
+		t.Run(fmt.Sprintf("parallel=%t", enableParallel), func(t *testing.T) {
+			config := DefaultParallelConfig()
+			config.EnableParallel = enableParallel
+			executor := NewParallelExecutor(config)
+			defer executor.Cleanup()
+
+			var mu sync.Mutex
+			executed := make(map[string]int)
+			recorded := make(map[int]int)
+			executor.onStepComplete = func(result *StepResult) {
+				mu.Lock()
+				recorded[result.StepIndex]++
+				mu.Unlock()
+			}
+
+			mockEngine := &MockWorkflowEngine{
+				executeFunc: func(step WorkflowStep, stepNum int) error {
+					mu.Lock()
+					executed[step.ID]++
+					mu.Unlock()
+					return nil
+				},
+			}
+
+			steps := []WorkflowStep{
+				{ID: "design"},
+				{ID: "build", DependsOn: []string{"design"}},
+				{ID: "review", DependsOn: []string{"build"}},
+			}
+
+			executor.RestoreResults(map[int]*StepResult{
+				0: {StepIndex: 0, Success: true},
+				1: {StepIndex: 1, Success: false, Error: fmt.Errorf("failed last time")},
+			})
+
+			if err := executor.ExecuteParallel(mockEngine, steps); err != nil {
+				t.Fatalf("Execution failed: %v", err)
+			}
+			// A step dispatched twice may still be running after the count completes
+			executor.wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			want := map[string]int{"build": 1, "review": 1}
+			if fmt.Sprint(executed) != fmt.Sprint(want) {
+				t.Errorf("Expected each pending step to run once, got %v", executed)
+			}
+			if fmt.Sprint(recorded) != fmt.Sprint(map[int]int{1: 1, 2: 1}) {
+				t.Errorf("Expected each run step to be checkpointed once, got %v", recorded)
+			}
+
+			if !executor.GetResults()[1].Success {
+				t.Error("Previously failed step should have been re-run")
+			}
+		})
+	}
+}
+
+func TestNewRunDir(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got := newRunDir("runs", "Complex Multi-Step BMAD Workflow", now)
+	expected := filepath.Join("runs", "complex-multi-step-bmad-workflow-20250102-030405")
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestCancelOnInterrupt(t *testing.T) {
+	cancelled := make(chan struct{})
+	stop := cancelOnInterrupt(func() { close(cancelled) })
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected an interrupt to cancel the run")
+	}
+}
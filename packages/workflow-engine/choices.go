@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// inlineOptionsPattern matches an option list written into a title, e.g.
+// "Repository Structure: {Monorepo|Polyrepo|Multi-repo}"
+var inlineOptionsPattern = regexp.MustCompile(`\{([^{}|]+(?:\|[^{}|]+)+)\}`)
+
+// choiceAnswer is a choice made while rendering a template
+type choiceAnswer struct {
+	name  string
+	value string
+}
+
+// choiceVariable returns the variable a choice is stored in: its name, or the
+// section id for a section's own choice list
+func choiceVariable(section TemplateSection, name string) string {
+	if name == "" {
+		return section.ID
+	}
+	return name
+}
+
+// choiceVariables returns the variables set by choices anywhere in sections
+func choiceVariables(sections []TemplateSection) []string {
+	var names []string
+	for _, section := range sections {
+		for name := range section.Choices {
+			names = append(names, choiceVariable(section, name))
+		}
+		names = append(names, choiceVariables(section.Sections)...)
+	}
+	return names
+}
+
+// processChoices resolves every choice set of a section, storing each answer
+// as a variable for the rest of the template. Values already defined (by
+// --var, the workflow or the answers file) are used without asking; otherwise
+// interactive runs present a numbered menu and yolo runs fall back to the
+// first option with a warning in the document. It returns the section's own
+// choice, if any.
+func (dp *DocumentProcessor) processChoices(section TemplateSection, interactive bool) (string, error) {
+	names := make([]string, 0, len(section.Choices))
+	for name := range section.Choices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	own := ""
+	for _, name := range names {
+		options := section.Choices[name]
+		variable := choiceVariable(section, name)
+
+		var value string
+		if existing, ok := dp.variableScope().Lookup(variable); ok {
+			selected, err := matchChoice(fmt.Sprintf("%v", existing), options, section.MultiSelect)
+			if err != nil {
+				return "", NewStepError(ErrorClassUserInput, err, "invalid value for choice %q", variable).
+					WithRemediation(fmt.Sprintf("Use one of: %s", strings.Join(options, ", ")))
+			}
+			value = selected
+		} else if interactive {
+			selected, err := dp.getChoiceInput(variable, options, section.MultiSelect)
+			if err != nil {
+				return "", err
+			}
+			value = selected
+		} else if len(options) > 0 {
+			value = options[0]
+			fmt.Printf("   ⚠️  No answer for choice %q; defaulting to %q\n", variable, value)
+			dp.addToOutput(fmt.Sprintf("> ⚠️ %s defaulted to %q (no answer recorded)", variable, value))
+			dp.addToOutput("")
+		}
+
+		fmt.Printf("   🔘 %s = %s\n", variable, value)
+		dp.choices = append(dp.choices, choiceAnswer{name: variable, value: value})
+		dp.recordAnswer(variable, value)
+		dp.scope = dp.variableScope().with(map[string]interface{}{variable: value})
+		if name == "" {
+			own = value
+		}
+	}
+	return own, nil
+}
+
+// getChoiceInput presents options as a numbered menu until the selection is valid.
+// Multi-select sections accept several comma-separated numbers.
+func (dp *DocumentProcessor) getChoiceInput(name string, options []string, multi bool) (string, error) {
+	fmt.Printf("   🔘 Choose %s:\n", name)
+	for i, option := range options {
+		fmt.Printf("   %d. %s\n", i+1, option)
+	}
+
+	prompt := fmt.Sprintf("Select 1-%d:", len(options))
+	if multi {
+		prompt = fmt.Sprintf("Select one or more of 1-%d (comma-separated):", len(options))
+	}
+
+	for {
+		input, err := dp.getUserInput(prompt)
+		if err != nil {
+			return "", err
+		}
+
+		var selected []string
+		valid := input != ""
+		for _, part := range strings.Split(input, ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || number < 1 || number > len(options) {
+				valid = false
+				break
+			}
+			selected = append(selected, options[number-1])
+		}
+		if valid && (multi || len(selected) == 1) {
+			return strings.Join(selected, ", "), nil
+		}
+		fmt.Printf("   Please enter a number from 1 to %d\n", len(options))
+	}
+}
+
+// matchChoice checks a recorded answer against the options, returning it in
+// the options' spelling; multi-select answers are comma-separated
+func matchChoice(value string, options []string, multi bool) (string, error) {
+	parts := []string{value}
+	if multi {
+		parts = strings.Split(value, ",")
+	}
+
+	var matched []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		found := false
+		for _, option := range options {
+			if strings.EqualFold(option, part) {
+				matched = append(matched, option)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%q is not one of %s", part, strings.Join(options, ", "))
+		}
+	}
+	return strings.Join(matched, ", "), nil
+}
+
+// applyChoices replaces inline option lists in text with the choice made from them
+func (dp *DocumentProcessor) applyChoices(text string) string {
+	return inlineOptionsPattern.ReplaceAllStringFunc(text, func(group string) string {
+		options := strings.Split(inlineOptionsPattern.FindStringSubmatch(group)[1], "|")
+		for _, choice := range dp.choices {
+			for _, option := range options {
+				if strings.EqualFold(strings.TrimSpace(option), choice.value) {
+					return choice.value
+				}
+			}
+		}
+		return group
+	})
+}
+
+// recordAnswer keeps a value entered or chosen in the step and checkpoints it,
+// so resuming an interrupted step does not ask for it again. Answers given
+// inside a repeatable instance differ per instance and are not kept.
+func (dp *DocumentProcessor) recordAnswer(name, value string) {
+	if dp.instances > 0 {
+		return
+	}
+	if dp.answers == nil {
+		dp.answers = map[string]interface{}{}
+	}
+	dp.answers[name] = value
+	if dp.onAnswer != nil {
+		dp.onAnswer(dp.answers)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func newChoicesTemplate() Template {
+	var template Template
+	template.Template.Output.Title = "PRD"
+	template.Sections = []TemplateSection{
+		{
+			ID:      "ui-goals",
+			Title:   "UI Goals",
+			Choices: TemplateChoices{"accessibility": {"None", "WCAG AA", "WCAG AAA"}},
+			Sections: []TemplateSection{
+				{ID: "accessibility", Title: "Accessibility: {None|WCAG AA|WCAG AAA|Custom Requirements}"},
+			},
+		},
+		{ID: "audit", Title: "Accessibility Audit ({{accessibility}})", Condition: `accessibility != "None"`},
+		{ID: "status", Title: "Status", Type: "choice", Choices: TemplateChoices{"": {"Draft", "Approved", "Done"}}},
+	}
+	return template
+}
+
+func renderChoices(t *testing.T, mode string, variables map[string]interface{}, input string) (*DocumentProcessor, error) {
+	t.Helper()
+
+	dp := newTestProcessor(true, variables, input)
+	dp.conditions = &ConditionEvaluator{mode: mode}
+	template, err := dp.scope.resolveTemplate(newChoicesTemplate())
+	if err != nil {
+		t.Fatalf("Choice variables should be deferred when resolving the template: %v", err)
+	}
+	return dp, dp.processTemplate(template, mode)
+}
+
+func TestProcessChoices_Interactive(t *testing.T) {
+	// accessibility: invalid, then WCAG AA; ui-goals and audit content; status: Approved
+	input := "7\n2\nui\ntitle\naudit\n2\n"
+	dp, err := renderChoices(t, "interactive", nil, input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	document := strings.Join(dp.output, "\n")
+	for _, want := range []string{"### Accessibility: WCAG AA", "## Accessibility Audit (WCAG AA)", "## Status\n\nApproved"} {
+		if !strings.Contains(document, want) {
+			t.Errorf("Expected %q in document:\n%s", want, document)
+		}
+	}
+}
+
+func TestProcessChoices_YoloUsesAnswersOrFirstOption(t *testing.T) {
+	dp, err := renderChoices(t, "yolo", map[string]interface{}{"accessibility": "wcag aaa"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	document := strings.Join(dp.output, "\n")
+	if !strings.Contains(document, "### Accessibility: WCAG AAA") {
+		t.Errorf("Expected the recorded answer in its canonical spelling:\n%s", document)
+	}
+	if !strings.Contains(document, "Draft") || !strings.Contains(document, `> ⚠️ status defaulted to "Draft"`) {
+		t.Errorf("Expected the first option with a warning for the unanswered choice:\n%s", document)
+	}
+
+	dp, err = renderChoices(t, "yolo", map[string]interface{}{"accessibility": "None", "status": "Done"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(strings.Join(dp.output, "\n"), "Accessibility Audit") {
+		t.Error("Expected the choice to drive the later section's condition")
+	}
+
+	if _, err := renderChoices(t, "yolo", map[string]interface{}{"accessibility": "Section 508"}, ""); ErrorClassOf(err) != ErrorClassUserInput {
+		t.Errorf("Expected a user-input error for an answer outside the options, got %v", err)
+	}
+}
+
+func TestGetChoiceInput_MultiSelect(t *testing.T) {
+	dp := newTestProcessor(false, nil, "1,x\n1, 3\n")
+
+	got, err := dp.getChoiceInput("platforms", []string{"Web", "iOS", "Android"}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "Web, Android" {
+		t.Errorf("Expected %q, got %q", "Web, Android", got)
+	}
+
+	if value, err := matchChoice("web,android", []string{"Web", "iOS", "Android"}, true); err != nil || value != "Web, Android" {
+		t.Errorf("Expected recorded multi-select answers to match, got %q, %v", value, err)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Answers holds pre-recorded answers that let yolo runs proceed without prompting
+type Answers struct {
+	// Conditions maps a section's free-text condition to whether it holds
+	Conditions map[string]bool `yaml:"conditions,omitempty"`
+	// Variables fill template placeholders, below any variables set in the workflow
+	Variables map[string]interface{} `yaml:"variables,omitempty"`
+	// Choices answer template choice sets by variable name
+	Choices map[string]interface{} `yaml:"choices,omitempty"`
+}
+
+// LoadAnswers reads an answers file
+func LoadAnswers(path string) (*Answers, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, NewStepError(ErrorClassFilesystem, err, "error reading answers file %s", path)
+	}
+
+	var answers Answers
+	if err := yaml.Unmarshal(data, &answers); err != nil {
+		return nil, NewStepError(ErrorClassUserInput, err, "error parsing answers file %s", path)
+	}
+	return &answers, nil
+}
+
+// condition returns the recorded answer for a free-text condition, ignoring case
+func (a *Answers) condition(text string) (bool, bool) {
+	if a == nil {
+		return false, false
+	}
+	for question, answer := range a.Conditions {
+		if strings.EqualFold(strings.TrimSpace(question), strings.TrimSpace(text)) {
+			return answer, true
+		}
+	}
+	return false, false
+}
+
+// withAnswers returns s with the answers file's variables and choices as its lowest layers
+func (s *VariableScope) withAnswers(answers *Answers) *VariableScope {
+	if answers == nil || (len(answers.Variables) == 0 && len(answers.Choices) == 0) {
+		return s
+	}
+	child := *s
+	child.layers = append(append([]map[string]interface{}{}, s.layers...), answers.Variables, answers.Choices)
+	return &child
+}
+
+// SkippedSection records a template section left out because its condition did not hold
+type SkippedSection struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Condition string `json:"condition"`
+	Reason    string `json:"reason"`
+}
+
+// ConditionEvaluator decides whether conditional template sections are rendered.
+// Conditions written as expressions over workflow variables (`has_ui`,
+// `api_style == "REST"`, `!legacy && platform != web`) are evaluated directly;
+// free-text conditions are looked up in the answers file or, in interactive
+// mode, asked as a yes/no question.
+type ConditionEvaluator struct {
+	answers *Answers
+	mode    string
+	confirm func(prompt string) (bool, error)
+}
+
+// Evaluate reports whether a condition holds in scope, with a human-readable reason
+func (c *ConditionEvaluator) Evaluate(condition string, scope *VariableScope) (bool, string, error) {
+	if expr, ok := parseConditionExpression(condition, scope); ok {
+		if expr.eval(scope) {
+			return true, "expression is true", nil
+		}
+		return false, fmt.Sprintf("expression %q is false", condition), nil
+	}
+
+	if answer, ok := c.answers.condition(condition); ok {
+		if answer {
+			return true, "answers file: yes", nil
+		}
+		return false, "answers file: no", nil
+	}
+
+	if c.mode == "yolo" || c.confirm == nil {
+		return true, "no recorded answer; included by default", nil
+	}
+
+	include, err := c.confirm(fmt.Sprintf("❓ Condition: %s", condition))
+	if err != nil {
+		return false, "", err
+	}
+	if include {
+		return true, "answered yes", nil
+	}
+	return false, "answered no", nil
+}
+
+// Condition expressions
+
+var conditionTokenPattern = regexp.MustCompile(`\s*(==|!=|&&|\|\||!|\(|\)|"[^"]*"|'[^']*'|[A-Za-z0-9_][A-Za-z0-9_.\-]*)`)
+
+type conditionExpr interface {
+	eval(scope *VariableScope) bool
+}
+
+type conditionOperand struct {
+	word    string
+	literal bool
+}
+
+type conditionNot struct{ expr conditionExpr }
+
+type conditionBinary struct {
+	op          string
+	left, right conditionExpr
+}
+
+type conditionCompare struct {
+	op          string
+	left, right conditionOperand
+}
+
+// value resolves an operand: quoted strings are literals, words are variables
+// when defined and literals otherwise
+func (o conditionOperand) value(scope *VariableScope) string {
+	if !o.literal {
+		if value, ok := scope.Lookup(o.word); ok {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return o.word
+}
+
+func (o conditionOperand) eval(scope *VariableScope) bool {
+	value, ok := scope.Lookup(o.word)
+	return ok && truthy(value)
+}
+
+func (n conditionNot) eval(scope *VariableScope) bool {
+	return !n.expr.eval(scope)
+}
+
+func (b conditionBinary) eval(scope *VariableScope) bool {
+	if b.op == "&&" {
+		return b.left.eval(scope) && b.right.eval(scope)
+	}
+	return b.left.eval(scope) || b.right.eval(scope)
+}
+
+func (cmp conditionCompare) eval(scope *VariableScope) bool {
+	equal := strings.EqualFold(cmp.left.value(scope), cmp.right.value(scope))
+	if cmp.op == "==" {
+		return equal
+	}
+	return !equal
+}
+
+// truthy interprets a variable value as a boolean
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "false", "no", "0", "off":
+			return false
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// parseConditionExpression parses a condition as an expression over variables.
+// It reports false for free text, including a lone word that names no variable.
+func parseConditionExpression(condition string, scope *VariableScope) (conditionExpr, bool) {
+	text := variablePattern.ReplaceAllString(condition, "$1")
+
+	var tokens []string
+	for rest := text; strings.TrimSpace(rest) != ""; {
+		loc := conditionTokenPattern.FindStringSubmatchIndex(rest)
+		if loc == nil || loc[0] != 0 {
+			return nil, false
+		}
+		tokens = append(tokens, rest[loc[2]:loc[3]])
+		rest = rest[loc[1]:]
+	}
+	if len(tokens) == 0 {
+		return nil, false
+	}
+
+	if len(tokens) == 1 {
+		if _, defined := scope.Lookup(tokens[0]); !defined {
+			return nil, false
+		}
+	}
+
+	p := &conditionParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil || p.pos != len(tokens) {
+		return nil, false
+	}
+	return expr, true
+}
+
+type conditionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *conditionParser) parseOr() (conditionExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionBinary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionBinary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionExpr, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return conditionNot{expr: expr}, nil
+	case "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("expected )")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peek(); op == "==" || op == "!=" {
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return conditionCompare{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionOperand, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return conditionOperand{}, fmt.Errorf("unexpected end of condition")
+	case strings.HasPrefix(token, `"`) || strings.HasPrefix(token, "'"):
+		return conditionOperand{word: token[1 : len(token)-1], literal: true}, nil
+	case strings.ContainsAny(token[:1], "=!&|()"):
+		return conditionOperand{}, fmt.Errorf("unexpected %q", token)
+	}
+
+	if _, err := strconv.ParseFloat(token, 64); err == nil {
+		return conditionOperand{word: token, literal: true}, nil
+	}
+	return conditionOperand{word: token}, nil
+}
+
+// includeSection evaluates a section's condition, recording it as skipped when false
+func (dp *DocumentProcessor) includeSection(section TemplateSection) (bool, error) {
+	if section.Condition == "" || dp.conditions == nil {
+		return true, nil
+	}
+
+	include, reason, err := dp.conditions.Evaluate(section.Condition, dp.variableScope())
+	if err != nil {
+		return false, err
+	}
+
+	if !include {
+		fmt.Printf("   ⏭️  Skipping section %q (%s): %s\n", section.Title, section.Condition, reason)
+		dp.skipped = append(dp.skipped, SkippedSection{
+			ID:        section.ID,
+			Title:     section.Title,
+			Condition: section.Condition,
+			Reason:    reason,
+		})
+	}
+	return include, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestConditionEvaluator(mode string, answers *Answers, input string) *ConditionEvaluator {
+	dp := newTestProcessor(false, nil, input)
+	return &ConditionEvaluator{answers: answers, mode: mode, confirm: dp.askYesNo}
+}
+
+func TestConditionEvaluator_Expressions(t *testing.T) {
+	variables := map[string]interface{}{
+		"has_ui":    true,
+		"legacy":    "no",
+		"api_style": "REST",
+		"replicas":  3,
+	}
+	c := newTestConditionEvaluator("yolo", nil, "")
+	scope := newTestScope(false, variables)
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"has_ui", true},
+		{"{{has_ui}}", true},
+		{"!has_ui", false},
+		{"legacy", false},
+		{`api_style == "REST"`, true},
+		{"api_style == graphql", false},
+		{"api_style != GraphQL && has_ui", true},
+		{"legacy || (replicas == 3 && !legacy)", true},
+		{"missing_flag || legacy", false},
+	}
+
+	for _, tt := range tests {
+		got, _, err := c.Evaluate(tt.condition, scope)
+		if err != nil {
+			t.Errorf("Evaluate(%q) failed: %v", tt.condition, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Evaluate(%q) = %t, want %t", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestConditionEvaluator_FreeText(t *testing.T) {
+	answers := &Answers{Conditions: map[string]bool{"PRD has UX/UI requirements": false}}
+
+	scope := newTestScope(false, nil)
+	yolo := newTestConditionEvaluator("yolo", answers, "")
+	if got, reason, _ := yolo.Evaluate("prd has ux/ui requirements", scope); got || reason != "answers file: no" {
+		t.Errorf("Expected the recorded answer to skip the section, got %t (%s)", got, reason)
+	}
+	if got, _, _ := yolo.Evaluate("API style is REST", scope); !got {
+		t.Error("Unanswered free-text conditions should be included by default in yolo mode")
+	}
+
+	interactive := newTestConditionEvaluator("interactive", nil, "maybe\nn\n")
+	got, reason, err := interactive.Evaluate("Has research findings", scope)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got || reason != "answered no" {
+		t.Errorf("Expected the user's answer after re-prompting, got %t (%s)", got, reason)
+	}
+
+	if _, _, err := interactive.Evaluate("Has stakeholder feedback", scope); err == nil {
+		t.Error("Expected an error once input is exhausted")
+	}
+}
+
+func TestExecuteTemplateTask_SkipsConditionalSections(t *testing.T) {
+	dir := t.TempDir()
+	templateYAML := `template:
+  id: prd
+  name: PRD
+  version: "1.0"
+  output:
+    format: markdown
+    filename: prd.md
+    title: PRD
+sections:
+  - id: goals
+    title: Goals
+  - id: ui-goals
+    title: UI Design Goals
+    condition: PRD has UX/UI requirements
+    sections:
+      - id: screens
+        title: Core Screens
+  - id: api
+    title: REST API
+    condition: api_style == REST
+`
+	answersYAML := "conditions:\n  PRD has UX/UI requirements: false\n"
+
+	templatePath := filepath.Join(dir, "prd-tmpl.yaml")
+	if err := ioutil.WriteFile(templatePath, []byte(templateYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "answers.yaml"), []byte(answersYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &WorkflowEngine{
+		processor:         newTestProcessor(false, nil, ""),
+		workflowVariables: map[string]interface{}{"api_style": "GraphQL"},
+		paths:             NewPathResolver(templatePath, PathConfig{}, nil, dir),
+	}
+
+	step := WorkflowStep{Template: templatePath, Mode: "yolo", Answers: "answers.yaml"}
+	output, err := engine.executeTemplateTask(step, 1)
+	if err != nil {
+		t.Fatalf("Template task failed: %v", err)
+	}
+
+	document := strings.Join(output.Document, "\n")
+	if !strings.Contains(document, "## Goals") {
+		t.Error("Unconditional section missing")
+	}
+	if strings.Contains(document, "UI Design Goals") || strings.Contains(document, "Core Screens") || strings.Contains(document, "REST API") {
+		t.Errorf("Skipped sections rendered:\n%s", document)
+	}
+
+	if len(output.SkippedSections) != 2 {
+		t.Fatalf("Expected 2 skipped sections in output metadata, got %+v", output.SkippedSections)
+	}
+	if skipped := output.SkippedSections[0]; skipped.ID != "ui-goals" || skipped.Reason != "answers file: no" {
+		t.Errorf("Unexpected skipped section record: %+v", skipped)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ContentProvider drafts section content for yolo runs
+type ContentProvider interface {
+	Generate(request ContentRequest) (string, error)
+}
+
+// ContentRequest asks a provider for the content of one section
+type ContentRequest struct {
+	Section TemplateSection
+	Prompt  string
+}
+
+// agentContentProvider drafts sections by running the step's agent through opencode
+type agentContentProvider struct {
+	runner  *OpenCodeRunner
+	ctx     context.Context
+	step    WorkflowStep
+	stepNum int
+}
+
+// Generate sends the section prompt to the step's agent and returns its reply
+func (p *agentContentProvider) Generate(request ContentRequest) (string, error) {
+	result, err := p.runner.RunPrompt(p.ctx, p.step, p.stepNum, request.Prompt)
+	if err != nil {
+		return "", err
+	}
+	return result.Stdout, nil
+}
+
+// isDraftable reports whether a yolo section's content comes from the content
+// provider; choices, diagrams and tables have their own sources
+func isDraftable(section TemplateSection) bool {
+	switch section.Type {
+	case "choice", "mermaid", "table":
+		return false
+	}
+	return true
+}
+
+// draftSection asks the content provider for a section's content and writes
+// it. Sections that only group subsections and carry no guidance are left
+// empty rather than drafted.
+func (dp *DocumentProcessor) draftSection(section TemplateSection) error {
+	if len(section.Sections) > 0 && section.Instruction == "" && section.Template == "" {
+		return nil
+	}
+
+	prompt, err := dp.sectionPrompt(section)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("   🤖 Drafting section: %s\n", section.Title)
+	content, err := dp.content.Generate(ContentRequest{Section: section, Prompt: prompt})
+	if err != nil {
+		return wrapStepError(ErrorClassAgent, err, "error drafting section %q", section.ID)
+	}
+
+	content = cleanDraft(content)
+	if content == "" {
+		return NewStepError(ErrorClassAgent, nil, "agent returned no content for section %q", section.ID).
+			WithRemediation("Check the agent's output, or run the step in interactive mode")
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		dp.addToOutput(line)
+	}
+	dp.addToOutput("")
+	return nil
+}
+
+// sectionPrompt builds the drafting prompt for a section from its instruction,
+// expected format, the workflow variables and the document rendered so far
+func (dp *DocumentProcessor) sectionPrompt(section TemplateSection) (string, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Draft the %q section of the document %q.\n", section.Title, dp.title)
+
+	if section.Instruction != "" {
+		fmt.Fprintf(&prompt, "\nInstructions:\n%s\n", strings.TrimSpace(section.Instruction))
+	}
+	fmt.Fprintf(&prompt, "\nFormat: %s\n", draftFormat(section))
+
+	if hasTemplateBody(section) {
+		body, err := dp.lenientScope().Interpolate(section.Template)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&prompt, "\nFollow this structure, filling in every {{placeholder}}:\n%s\n", strings.TrimSpace(body))
+	}
+	for _, example := range section.Examples {
+		fmt.Fprintf(&prompt, "\nExample:\n%s\n", strings.TrimSpace(example))
+	}
+
+	if variables := dp.variableScope().flatten(); len(variables) > 0 {
+		names := make([]string, 0, len(variables))
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		prompt.WriteString("\nVariables:\n")
+		for _, name := range names {
+			fmt.Fprintf(&prompt, "- %s: %v\n", name, variables[name])
+		}
+	}
+
+	if document := strings.TrimSpace(strings.Join(dp.output, "\n")); document != "" {
+		fmt.Fprintf(&prompt, "\nDocument so far:\n%s\n", document)
+	}
+
+	prompt.WriteString("\nReply with the section content only, in markdown, without the section heading.")
+	return prompt.String(), nil
+}
+
+// draftFormat describes the content expected for a section type
+func draftFormat(section TemplateSection) string {
+	switch section.Type {
+	case "bullet-list":
+		return "a markdown bullet list"
+	case "numbered-list":
+		if section.Prefix != "" {
+			return fmt.Sprintf("a bullet list of items identified as %s1, %s2, ... (`- %s1: ...`)", section.Prefix, section.Prefix, section.Prefix)
+		}
+		return "a markdown numbered list"
+	case "paragraphs":
+		return "one or more paragraphs"
+	case "code":
+		return fmt.Sprintf("a single ```%s code block", section.Language)
+	default:
+		return "markdown"
+	}
+}
+
+// cleanDraft trims an agent reply and removes a fence wrapping the whole reply
+// or a repeated section heading
+func cleanDraft(content string) string {
+	content = strings.TrimSpace(content)
+
+	lines := strings.Split(content, "\n")
+	if len(lines) >= 2 && (lines[0] == "```markdown" || lines[0] == "```md") && lines[len(lines)-1] == "```" {
+		lines = lines[1 : len(lines)-1]
+	}
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "#") {
+		lines = lines[1:]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
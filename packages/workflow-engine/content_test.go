@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// stubContentProvider returns canned content per section id and records prompts
+type stubContentProvider struct {
+	content map[string]string
+	prompts map[string]string
+}
+
+func (p *stubContentProvider) Generate(request ContentRequest) (string, error) {
+	if p.prompts == nil {
+		p.prompts = map[string]string{}
+	}
+	p.prompts[request.Section.ID] = request.Prompt
+	return p.content[request.Section.ID], nil
+}
+
+func newDraftTemplate() Template {
+	var template Template
+	template.Template.Output.Title = "Acme PRD"
+	template.Sections = []TemplateSection{
+		{ID: "goals", Title: "Goals", Type: "bullet-list", Instruction: "List the desired outcomes"},
+		{ID: "requirements", Title: "Requirements", Sections: []TemplateSection{
+			{ID: "functional", Title: "Functional", Type: "numbered-list", Prefix: "FR", Instruction: "Each requirement on its own line"},
+		}},
+		{ID: "status", Title: "Status", Type: "choice", Choices: TemplateChoices{"": {"Draft", "Approved"}}},
+	}
+	return template
+}
+
+func TestDraftSection_UsesContentProvider(t *testing.T) {
+	provider := &stubContentProvider{content: map[string]string{
+		"goals":      "- Cut onboarding time in half\n- Self-serve signup",
+		"functional": "```markdown\n### Functional\n- FR1: Users can sign up with email\n```",
+	}}
+
+	dp := newTestProcessor(false, map[string]interface{}{"project_name": "Acme", "status": "Approved"}, "")
+	dp.content = provider
+	if err := dp.processTemplate(newDraftTemplate(), "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "# Acme PRD\n\n## Goals\n\n- Cut onboarding time in half\n- Self-serve signup\n\n## Requirements\n\n### Functional\n\n- FR1: Users can sign up with email\n\n## Status\n\nApproved\n"
+	if got := strings.Join(dp.output, "\n"); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	if _, drafted := provider.prompts["requirements"]; drafted {
+		t.Error("Expected a grouping section without instructions not to be drafted")
+	}
+	if _, drafted := provider.prompts["status"]; drafted {
+		t.Error("Expected choice sections to keep their own content")
+	}
+
+	prompt := provider.prompts["functional"]
+	for _, want := range []string{
+		`Draft the "Functional" section of the document "Acme PRD"`,
+		"Each requirement on its own line",
+		"`- FR1: ...`",
+		"- project_name: Acme",
+		"- Self-serve signup",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDraftSection_EmptyReplyIsAgentError(t *testing.T) {
+	dp := newTestProcessor(false, nil, "")
+	dp.content = &stubContentProvider{}
+
+	err := dp.draftSection(TemplateSection{ID: "goals", Title: "Goals", Instruction: "List goals"})
+	if ErrorClassOf(err) != ErrorClassAgent {
+		t.Errorf("Expected an agent error, got %v", err)
+	}
+}
+
+func TestAgentContentProvider_RunsStepAgent(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+	provider := &agentContentProvider{
+		runner:  runner,
+		ctx:     context.Background(),
+		step:    WorkflowStep{Agent: "pm", Task: "create-doc", Prompt: "Create the PRD"},
+		stepNum: 2,
+	}
+
+	content, err := provider.Generate(ContentRequest{Prompt: "Draft the Goals section"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(content, "fake-opencode run @pm create-doc\nDraft the Goals section") {
+		t.Errorf("Expected the section prompt to reach the agent on stdin, got %q", content)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// elicitationMethodsFile is the BMAD data file the elicitation menu is built from
+const elicitationMethodsFile = "data/elicitation-methods.md"
+
+// ElicitationMethod is one method from the elicitation methods data file
+type ElicitationMethod struct {
+	Name     string
+	Category string
+	Steps    []string
+}
+
+// ElicitationMenu replaces the elicitation menu for a template or section
+type ElicitationMenu struct {
+	Title   string   `yaml:"title"`
+	Options []string `yaml:"options"`
+}
+
+// defaultElicitationMethods are used when the data file cannot be found
+var defaultElicitationMethods = []ElicitationMethod{
+	{Name: "Expand or Contract for Audience", Category: "Core Reflective Methods", Steps: []string{"Should the section be expanded or simplified, and for which audience?"}},
+	{Name: "Critique and Refine", Category: "Core Reflective Methods", Steps: []string{"What flaws, inconsistencies or gaps does the section have?"}},
+	{Name: "Identify Potential Risks and Unforeseen Issues", Category: "Risk and Challenge Methods", Steps: []string{"What risks, edge cases or unintended consequences are missing?"}},
+	{Name: "Proceed / No Further Actions", Category: "Process Control"},
+}
+
+// parseElicitationMethods reads methods from the data file: `## Category`
+// headings, `**Method Name**` lines and the method's bullet points
+func parseElicitationMethods(data string) []ElicitationMethod {
+	var methods []ElicitationMethod
+	category := ""
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "## "):
+			category = strings.TrimSpace(strings.TrimPrefix(line, "## "))
+		case strings.HasPrefix(line, "**") && strings.HasSuffix(line, "**") && len(line) > 4:
+			methods = append(methods, ElicitationMethod{Name: strings.Trim(line, "* "), Category: category})
+		case strings.HasPrefix(line, "- ") && len(methods) > 0:
+			method := &methods[len(methods)-1]
+			method.Steps = append(method.Steps, strings.TrimSpace(strings.TrimPrefix(line, "- ")))
+		}
+	}
+	return methods
+}
+
+// isProceedOption reports whether a menu option ends elicitation
+func isProceedOption(option string) bool {
+	return strings.HasPrefix(strings.ToLower(option), "proceed")
+}
+
+// elicitationMethods returns the methods from the data file, loading it once
+func (dp *DocumentProcessor) elicitationMethods() []ElicitationMethod {
+	if dp.methods != nil {
+		return dp.methods
+	}
+
+	dp.methods = defaultElicitationMethods
+	if dp.paths == nil {
+		return dp.methods
+	}
+
+	path, err := dp.paths.Resolve(elicitationMethodsFile)
+	if err == nil {
+		var data []byte
+		if data, err = ioutil.ReadFile(path); err == nil {
+			if methods := parseElicitationMethods(string(data)); len(methods) > 0 {
+				fmt.Printf("   📂 Elicitation methods: %s\n", path)
+				dp.methods = methods
+				return dp.methods
+			}
+		}
+	}
+	fmt.Printf("   ⚠️  Elicitation methods not loaded (%v); using the built-in set\n", err)
+	return dp.methods
+}
+
+// elicitationMenuFor returns the menu title and methods for a section: its own
+// custom_elicitation, else the template's, else the data file's methods. The
+// proceed option is always last.
+func (dp *DocumentProcessor) elicitationMenuFor(section TemplateSection) (string, []ElicitationMethod) {
+	custom := section.Elicitation
+	if custom == nil {
+		custom = dp.menu
+	}
+
+	title := "Advanced Elicitation Options"
+	var methods []ElicitationMethod
+	if custom != nil && len(custom.Options) > 0 {
+		if custom.Title != "" {
+			title = custom.Title
+		}
+		for _, option := range custom.Options {
+			methods = append(methods, ElicitationMethod{Name: option})
+		}
+	} else {
+		methods = append(methods, dp.elicitationMethods()...)
+	}
+
+	var menu []ElicitationMethod
+	proceed := ElicitationMethod{Name: "Proceed to next section"}
+	for _, method := range methods {
+		if isProceedOption(method.Name) {
+			proceed = method
+			continue
+		}
+		menu = append(menu, method)
+	}
+	return title, append(menu, proceed)
+}
+
+// handleElicitation runs elicitation cycles on a section's content, starting
+// at output line start, until the user picks the proceed option. Each chosen
+// method (or typed feedback) is run as a follow-up prompt cycle whose result
+// may revise the section; the exchange is kept in the elicitation transcript.
+func (dp *DocumentProcessor) handleElicitation(section TemplateSection, start int) error {
+	title, menu := dp.elicitationMenuFor(section)
+	dp.addTranscript(fmt.Sprintf("## %s", section.Title), "")
+
+	for {
+		fmt.Printf("   \n   📋 %s\n", title)
+		for i, method := range menu {
+			fmt.Printf("   %d. %s\n", i+1, method.Name)
+		}
+
+		input, err := dp.getUserInput(fmt.Sprintf("Select 1-%d or type your feedback:", len(menu)))
+		if err != nil {
+			return err
+		}
+		if input == "" {
+			continue
+		}
+
+		choice, err := strconv.Atoi(input)
+		switch {
+		case err != nil:
+			fmt.Printf("   💬 Feedback: %s\n", input)
+			dp.addTranscript("### Feedback", "", "> "+input, "")
+			err = dp.runElicitation(section, start, ElicitationMethod{Name: "Feedback", Steps: []string{input}}, true)
+		case choice < 1 || choice > len(menu):
+			fmt.Printf("   Please enter a number from 1 to %d\n", len(menu))
+			continue
+		case choice == len(menu):
+			fmt.Printf("   ✅ Proceeding to next section\n")
+			dp.addTranscript(fmt.Sprintf("_%s_", menu[choice-1].Name), "")
+			return nil
+		default:
+			method := menu[choice-1]
+			fmt.Printf("   🔍 %s\n", method.Name)
+			dp.addTranscript("### "+method.Name, "")
+			err = dp.runElicitation(section, start, method, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runElicitation applies a method to the section. With an agent, the agent
+// revises the section and the user accepts or rejects the revision; without
+// one, the user answers the method's prompts and may enter revised content.
+func (dp *DocumentProcessor) runElicitation(section TemplateSection, start int, method ElicitationMethod, feedback bool) error {
+	current := strings.Join(dp.output[start:], "\n")
+
+	if dp.content != nil {
+		prompt := elicitationPrompt(section, dp.title, current, method, feedback)
+		reply, err := dp.content.Generate(ContentRequest{Section: section, Prompt: prompt})
+		if err != nil {
+			return wrapStepError(ErrorClassAgent, err, "error running elicitation for section %q", section.ID)
+		}
+		revision := cleanDraft(reply)
+
+		fmt.Printf("   📝 Proposed revision:\n")
+		for _, line := range strings.Split(revision, "\n") {
+			fmt.Printf("   │ %s\n", line)
+		}
+		dp.addTranscript("Proposed revision:", "", revision, "")
+
+		accept, err := dp.askYesNo("Apply this revision")
+		if err != nil {
+			return err
+		}
+		if accept && revision != "" {
+			dp.reviseSection(start, strings.Split(revision, "\n"))
+			dp.addTranscript("Revision applied.", "")
+		} else {
+			dp.addTranscript("Revision rejected.", "")
+		}
+		return nil
+	}
+
+	if !feedback {
+		steps := method.Steps
+		if len(steps) == 0 {
+			steps = []string{method.Name}
+		}
+		for _, step := range steps {
+			answer, err := dp.getUserInput(fmt.Sprintf("▸ %s\n   >", step))
+			if err != nil {
+				return err
+			}
+			if answer != "" {
+				dp.addTranscript(fmt.Sprintf("- %s: %s", step, answer))
+			}
+		}
+		dp.addTranscript("")
+	}
+
+	lines, err := dp.getListInput("Enter the revised section content (empty line to finish; nothing keeps the current content):")
+	if err != nil {
+		return err
+	}
+	if len(lines) > 0 {
+		dp.reviseSection(start, lines)
+		dp.addTranscript("Revision applied.", "")
+	} else {
+		dp.addTranscript("Content kept.", "")
+	}
+	return nil
+}
+
+// elicitationPrompt builds the agent prompt for applying a method to a section
+func elicitationPrompt(section TemplateSection, document, current string, method ElicitationMethod, feedback bool) string {
+	var prompt strings.Builder
+	if feedback {
+		fmt.Fprintf(&prompt, "Revise the %q section of the document %q with this feedback:\n%s\n", section.Title, document, method.Steps[0])
+	} else {
+		fmt.Fprintf(&prompt, "Apply the elicitation method %q to the %q section of the document %q.\n", method.Name, section.Title, document)
+		for _, step := range method.Steps {
+			fmt.Fprintf(&prompt, "- %s\n", step)
+		}
+	}
+	if section.Instruction != "" {
+		fmt.Fprintf(&prompt, "\nSection instructions:\n%s\n", strings.TrimSpace(section.Instruction))
+	}
+	fmt.Fprintf(&prompt, "\nCurrent section content:\n%s\n", strings.TrimSpace(current))
+	prompt.WriteString("\nReply with the revised section content only, in markdown, without the section heading.")
+	return prompt.String()
+}
+
+// reviseSection replaces the section content written from output line start
+func (dp *DocumentProcessor) reviseSection(start int, lines []string) {
+	dp.output = append(dp.output[:start], lines...)
+	dp.addToOutput("")
+}
+
+// addTranscript appends lines to the elicitation transcript
+func (dp *DocumentProcessor) addTranscript(lines ...string) {
+	dp.transcript = append(dp.transcript, lines...)
+}
+
+// transcriptPath returns the file the elicitation transcript of a document is
+// saved to, next to it: docs/prd.md -> docs/prd.elicitation.md
+func transcriptPath(document string) string {
+	ext := filepath.Ext(document)
+	return strings.TrimSuffix(document, ext) + ".elicitation" + ext
+}
+
+// saveTranscript writes the elicitation transcript next to the document
+func (dp *DocumentProcessor) saveTranscript(document string) (string, error) {
+	path := transcriptPath(document)
+	content := fmt.Sprintf("# Elicitation Transcript: %s\n\n%s\n", dp.title, strings.TrimSpace(strings.Join(dp.transcript, "\n")))
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newElicitationProcessor(input string, content ContentProvider) *DocumentProcessor {
+	dp := newTestProcessor(false, nil, input)
+	dp.conditions = &ConditionEvaluator{mode: "interactive"}
+	dp.content = content
+	return dp
+}
+
+func TestParseElicitationMethods(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "bmad-core", "data", "elicitation-methods.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	methods := parseElicitationMethods(string(data))
+	if len(methods) != 20 {
+		t.Fatalf("Expected 20 methods, got %d", len(methods))
+	}
+
+	first := methods[0]
+	if first.Name != "Expand or Contract for Audience" || first.Category != "Core Reflective Methods" || len(first.Steps) != 3 {
+		t.Errorf("Unexpected first method: %+v", first)
+	}
+	if last := methods[len(methods)-1]; !isProceedOption(last.Name) {
+		t.Errorf("Expected the proceed method last, got %q", last.Name)
+	}
+}
+
+func TestHandleElicitation_RevisesSectionUntilProceed(t *testing.T) {
+	var template Template
+	template.Template.Output.Title = "Brief"
+	template.Sections = []TemplateSection{
+		{ID: "problem", Title: "Problem Statement", Type: "paragraphs", Elicit: true},
+	}
+
+	// content; Critique and Refine with one answer and a revision; proceed
+	input := "Users churn.\n2\nToo vague\nUsers churn after the first week.\n\n4\n"
+	dp := newElicitationProcessor(input, nil)
+	if err := dp.processTemplate(template, "interactive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "# Brief\n\n## Problem Statement\n\nUsers churn after the first week.\n"
+	if got := strings.Join(dp.output, "\n"); got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	transcript := strings.Join(dp.transcript, "\n")
+	for _, want := range []string{"## Problem Statement", "### Critique and Refine", "Too vague", "Revision applied.", "_Proceed / No Further Actions_"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("Expected %q in transcript:\n%s", want, transcript)
+		}
+	}
+}
+
+func TestHandleElicitation_CustomMenuWithAgent(t *testing.T) {
+	var template Template
+	template.Template.Output.Title = "Competitive Analysis"
+	template.Workflow.CustomElicitation = &ElicitationMenu{
+		Title:   "Competitive Analysis Elicitation Actions",
+		Options: []string{"Proceed to next section", "Stress test differentiation claims"},
+	}
+	template.Sections = []TemplateSection{
+		{ID: "summary", Title: "Executive Summary", Elicit: true},
+	}
+
+	provider := &stubContentProvider{content: map[string]string{"summary": "We win on price and onboarding."}}
+	// content; stress test, accept; feedback, reject; proceed
+	input := "We win on price.\n1\ny\nmention support\nn\n2\n"
+	dp := newElicitationProcessor(input, provider)
+	if err := dp.processTemplate(template, "interactive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := strings.Join(dp.output, "\n"); !strings.Contains(got, "## Executive Summary\n\nWe win on price and onboarding.\n") {
+		t.Errorf("Expected the accepted revision:\n%s", got)
+	}
+
+	prompt := provider.prompts["summary"]
+	if !strings.Contains(prompt, "with this feedback:\nmention support") || !strings.Contains(prompt, "We win on price and onboarding.") {
+		t.Errorf("Expected the feedback prompt to carry the revised content:\n%s", prompt)
+	}
+
+	transcript := strings.Join(dp.transcript, "\n")
+	if !strings.Contains(transcript, "### Stress test differentiation claims") || !strings.Contains(transcript, "Revision rejected.") {
+		t.Errorf("Unexpected transcript:\n%s", transcript)
+	}
+}
+
+func TestSaveTranscript_NextToDocument(t *testing.T) {
+	document := filepath.Join(t.TempDir(), "brief.md")
+	dp := &DocumentProcessor{title: "Brief", transcript: []string{"## Problem", "", "### Feedback"}}
+
+	path, err := dp.saveTranscript(document)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "brief.elicitation.md" {
+		t.Errorf("Expected brief.elicitation.md, got %s", path)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if !strings.HasPrefix(string(data), "# Elicitation Transcript: Brief\n\n## Problem") {
+		t.Errorf("Unexpected transcript file:\n%s", data)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorClass categorizes workflow failures so retries, exit codes and the
+// execution summary can key off the kind of failure instead of its message
+type ErrorClass string
+
+const (
+	ErrorClassTemplate           ErrorClass = "template"
+	ErrorClassFilesystem         ErrorClass = "filesystem"
+	ErrorClassAgent              ErrorClass = "agent"
+	ErrorClassWorkflowValidation ErrorClass = "workflow-validation"
+	ErrorClassUserInput          ErrorClass = "user-input"
+	ErrorClassThreshold          ErrorClass = "threshold"
+	ErrorClassTimeout            ErrorClass = "timeout"
+	ErrorClassCancelled          ErrorClass = "cancelled"
+	ErrorClassUnknown            ErrorClass = "unknown"
+)
+
+// ErrorSeverity ranks how serious a failure is
+type ErrorSeverity string
+
+const (
+	SeverityLow      ErrorSeverity = "low"
+	SeverityMedium   ErrorSeverity = "medium"
+	SeverityHigh     ErrorSeverity = "high"
+	SeverityCritical ErrorSeverity = "critical"
+)
+
+// errorClassDefaults holds the default severity and remediation for each class
+var errorClassDefaults = map[ErrorClass]struct {
+	severity    ErrorSeverity
+	remediation string
+}{
+	ErrorClassTemplate:           {SeverityHigh, "Check the template YAML structure and the sections it declares"},
+	ErrorClassFilesystem:         {SeverityHigh, "Check that the file exists and that its directory is readable and writable"},
+	ErrorClassAgent:              {SeverityMedium, "Check that the opencode CLI is installed (or OPENCODE_BIN is set) and the agent is configured; transient failures can be retried"},
+	ErrorClassWorkflowValidation: {SeverityCritical, "Fix the workflow definition and run it again"},
+	ErrorClassUserInput:          {SeverityMedium, "Provide the requested input, or run the step in yolo mode"},
+	ErrorClassThreshold:          {SeverityHigh, "Fix the failed checklist items, or lower the step's threshold"},
+	ErrorClassTimeout:            {SeverityHigh, "Increase parallel.timeout_duration or add a retry policy for the step"},
+	ErrorClassCancelled:          {SeverityLow, "Continue the run with `workflow-engine resume <run-dir>`"},
+	ErrorClassUnknown:            {SeverityMedium, "Inspect the step log for details"},
+}
+
+// StepError is a classified workflow failure with a suggested remediation
+type StepError struct {
+	Class       ErrorClass
+	Severity    ErrorSeverity
+	Message     string
+	Remediation string
+	Err         error
+}
+
+// NewStepError creates a classified error with the class's default severity and remediation
+func NewStepError(class ErrorClass, err error, format string, args ...interface{}) *StepError {
+	defaults, ok := errorClassDefaults[class]
+	if !ok {
+		defaults = errorClassDefaults[ErrorClassUnknown]
+	}
+
+	return &StepError{
+		Class:       class,
+		Severity:    defaults.severity,
+		Message:     fmt.Sprintf(format, args...),
+		Remediation: defaults.remediation,
+		Err:         err,
+	}
+}
+
+// WithRemediation replaces the suggested remediation
+func (e *StepError) WithRemediation(remediation string) *StepError {
+	e.Remediation = remediation
+	return e
+}
+
+func (e *StepError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// wrapStepError adds context to err. Errors that are already classified keep
+// their class; anything else is classified as class.
+func wrapStepError(class ErrorClass, err error, format string, args ...interface{}) error {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+	}
+	return NewStepError(class, err, format, args...)
+}
+
+// contextError classifies a context cancellation as a timeout or a cancellation
+func contextError(err error, format string, args ...interface{}) *StepError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewStepError(ErrorClassTimeout, err, format, args...)
+	}
+	return NewStepError(ErrorClassCancelled, err, format, args...)
+}
+
+// ErrorClassOf returns the class of the first StepError in err's chain
+func ErrorClassOf(err error) ErrorClass {
+	var stepErr *StepError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &stepErr):
+		return stepErr.Class
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCancelled
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// describeError returns the severity and remediation for err
+func describeError(err error) (ErrorSeverity, string) {
+	var stepErr *StepError
+	if errors.As(err, &stepErr) {
+		return stepErr.Severity, stepErr.Remediation
+	}
+
+	defaults := errorClassDefaults[ErrorClassOf(err)]
+	return defaults.severity, defaults.remediation
+}
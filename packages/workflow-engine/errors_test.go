@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestErrorClassOf(t *testing.T) {
+	agentErr := NewStepError(ErrorClassAgent, nil, "opencode exited with code 1")
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ""},
+		{"step error", agentErr, ErrorClassAgent},
+		{"wrapped step error", fmt.Errorf("step 3: %w", agentErr), ErrorClassAgent},
+		{"deadline", fmt.Errorf("run: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{"cancelled", context.Canceled, ErrorClassCancelled},
+		{"plain", errors.New("boom"), ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClassOf(tt.err); got != tt.want {
+				t.Errorf("Expected class %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWrapStepError_KeepsClass(t *testing.T) {
+	inner := NewStepError(ErrorClassFilesystem, os.ErrNotExist, "error reading template file x.yaml")
+
+	err := wrapStepError(ErrorClassTemplate, inner, "error processing template")
+	if ErrorClassOf(err) != ErrorClassFilesystem {
+		t.Errorf("Expected wrapped error to keep class filesystem, got %q", ErrorClassOf(err))
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("Expected the underlying cause to remain reachable through errors.Is")
+	}
+
+	plain := wrapStepError(ErrorClassTemplate, errors.New("bad yaml"), "error parsing template")
+	var stepErr *StepError
+	if !errors.As(plain, &stepErr) || stepErr.Class != ErrorClassTemplate {
+		t.Fatalf("Expected unclassified error to become a template StepError, got %v", plain)
+	}
+	if stepErr.Severity != SeverityHigh || stepErr.Remediation == "" {
+		t.Errorf("Expected class defaults for severity and remediation, got %+v", stepErr)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	if class := contextError(context.DeadlineExceeded, "step timed out").Class; class != ErrorClassTimeout {
+		t.Errorf("Expected timeout class, got %q", class)
+	}
+	if class := contextError(context.Canceled, "step cancelled").Class; class != ErrorClassCancelled {
+		t.Errorf("Expected cancelled class, got %q", class)
+	}
+}
+
+func TestExitCodeForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", NewStepError(ErrorClassWorkflowValidation, nil, "unknown step"), exitInvalid},
+		{"timeout", fmt.Errorf("step 1: %w", NewStepError(ErrorClassTimeout, nil, "timed out")), exitTimeout},
+		{"cancelled", NewStepError(ErrorClassCancelled, nil, "cancelled"), exitCancelled},
+		{"agent", NewStepError(ErrorClassAgent, nil, "exit 1"), exitFailure},
+		{"partial", &PartialFailureError{Failed: []int{1}, Succeeded: 1, Total: 2}, exitPartialSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeForError(tt.err); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChecklistEvaluator evaluates a checklist section's items against a document
+type ChecklistEvaluator interface {
+	Evaluate(request EvaluationRequest) ([]ItemEvaluation, error)
+}
+
+// EvaluationRequest asks an evaluator for the status of a section's items
+type EvaluationRequest struct {
+	Section  ChecklistSection
+	Guidance string
+	Document string
+	Prompt   string
+}
+
+// ItemEvaluation is an evaluator's verdict on one checklist item; evidence
+// holds the document line numbers that support it
+type ItemEvaluation struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Rationale string `json:"rationale"`
+	Evidence  []int  `json:"evidence,omitempty"`
+}
+
+// agentChecklistEvaluator evaluates sections by running the step's agent through opencode
+type agentChecklistEvaluator struct {
+	runner  *OpenCodeRunner
+	ctx     context.Context
+	step    WorkflowStep
+	stepNum int
+}
+
+// Evaluate sends the evaluation prompt to the step's agent and parses its reply
+func (a *agentChecklistEvaluator) Evaluate(request EvaluationRequest) ([]ItemEvaluation, error) {
+	result, err := a.runner.RunPrompt(a.ctx, a.step, a.stepNum, request.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	return parseItemEvaluations(result.Stdout)
+}
+
+// parseItemEvaluations reads the JSON array of item evaluations from an agent
+// reply, ignoring any text or code fence around it
+func parseItemEvaluations(reply string) ([]ItemEvaluation, error) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, NewStepError(ErrorClassAgent, nil, "agent reply has no JSON array of item evaluations").
+			WithRemediation("Check the agent's output, or run the checklist in interactive mode")
+	}
+
+	var evaluations []ItemEvaluation
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &evaluations); err != nil {
+		return nil, NewStepError(ErrorClassAgent, err, "error parsing item evaluations from the agent reply").
+			WithRemediation("Check the agent's output, or run the checklist in interactive mode")
+	}
+	return evaluations, nil
+}
+
+// evaluationPrompt builds the prompt for evaluating a section's pending items
+// against the target document, whose lines are numbered so they can be cited.
+// The checklist's [[LLM: ...]] guidance is sent ahead of it as system guidance.
+func evaluationPrompt(section ChecklistSection, items []ChecklistItem, guidance, document string, lines []string) string {
+	var prompt strings.Builder
+	if guidance != "" {
+		fmt.Fprintf(&prompt, "System guidance:\n%s\n\n", guidance)
+	}
+
+	if document != "" {
+		fmt.Fprintf(&prompt, "Evaluate the document %q against the checklist section %q.\n", document, section.Title)
+	} else {
+		fmt.Fprintf(&prompt, "Evaluate the project against the checklist section %q.\n", section.Title)
+	}
+	prompt.WriteString("\nItems:\n")
+	for _, item := range items {
+		fmt.Fprintf(&prompt, "- [%s] %s", item.ID, item.Text)
+		if item.Criteria != "" {
+			fmt.Fprintf(&prompt, ": %s", item.Criteria)
+		}
+		prompt.WriteString("\n")
+		if item.Guidance != "" {
+			fmt.Fprintf(&prompt, "  Guidance: %s\n", strings.ReplaceAll(item.Guidance, "\n", " "))
+		}
+	}
+
+	if len(lines) > 0 {
+		prompt.WriteString("\nDocument (each line starts with its line number):\n")
+		for i, line := range lines {
+			fmt.Fprintf(&prompt, "%d: %s\n", i+1, line)
+		}
+	}
+
+	prompt.WriteString("\nReply with a JSON array holding one object per item, for example\n")
+	prompt.WriteString(`[{"id": "1.1", "status": "pass", "rationale": "why", "evidence": [12, 14]}]` + "\n")
+	prompt.WriteString("status is one of pass, partial, fail or n/a; evidence lists the document line numbers that support it.")
+	return prompt.String()
+}
+
+// evaluationStatuses maps the statuses an evaluator may reply with to item statuses
+var evaluationStatuses = map[string]string{"pass": "pass", "partial": "partial", "fail": "fail", "n/a": "n/a", "na": "n/a"}
+
+// applyEvaluation returns the item with an evaluation's status, and its
+// rationale and cited lines as notes. Unknown statuses leave the item pending.
+func applyEvaluation(item ChecklistItem, evaluation ItemEvaluation, lines []string) ChecklistItem {
+	status, ok := evaluationStatuses[strings.ToLower(strings.TrimSpace(evaluation.Status))]
+	if !ok {
+		item.Status = "pending"
+		item.Notes = fmt.Sprintf("Not evaluated: unknown status %q", evaluation.Status)
+		return item
+	}
+	item.Status = status
+
+	notes := strings.TrimSpace(evaluation.Rationale)
+	var cited []string
+	for _, number := range evaluation.Evidence {
+		if number < 1 || number > len(lines) {
+			continue
+		}
+		cited = append(cited, fmt.Sprintf("L%d %q", number, truncate(strings.TrimSpace(lines[number-1]), 80)))
+	}
+	if len(cited) > 0 {
+		notes = strings.TrimSpace(notes + " Evidence: " + strings.Join(cited, "; "))
+	}
+	item.Notes = notes
+	return item
+}
+
+// truncate shortens text to at most n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// evaluateSection evaluates a section's unchecked items and records the results
+func (cp *ChecklistProcessor) evaluateSection(section ChecklistSection) error {
+	var pending []ChecklistItem
+	for _, item := range section.Items {
+		if !checkedInSource(item) {
+			pending = append(pending, item)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	guidance := cp.guidanceFor(section)
+	prompt := evaluationPrompt(section, pending, guidance, cp.document, cp.lines)
+
+	fmt.Printf("   🤖 Evaluating section: %s (%d items)\n", section.Title, len(pending))
+	evaluations, err := cp.evaluator.Evaluate(EvaluationRequest{Section: section, Guidance: guidance, Document: cp.document, Prompt: prompt})
+	if err != nil {
+		return wrapStepError(ErrorClassAgent, err, "error evaluating checklist section %q", section.Title)
+	}
+
+	byID := make(map[string]ItemEvaluation, len(evaluations))
+	for _, evaluation := range evaluations {
+		byID[evaluation.ID] = evaluation
+	}
+	for _, item := range pending {
+		evaluation, ok := byID[item.ID]
+		if !ok {
+			item.Notes = "Not evaluated: the evaluator returned no result for this item"
+			cp.results[item.ID] = item
+			continue
+		}
+		cp.results[item.ID] = applyEvaluation(item, evaluation, cp.lines)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// stubChecklistEvaluator returns canned evaluations and records the requests it gets
+type stubChecklistEvaluator struct {
+	evaluations map[string]ItemEvaluation
+	requests    []EvaluationRequest
+}
+
+func (s *stubChecklistEvaluator) Evaluate(request EvaluationRequest) ([]ItemEvaluation, error) {
+	s.requests = append(s.requests, request)
+
+	var evaluations []ItemEvaluation
+	for _, item := range request.Section.Items {
+		if evaluation, ok := s.evaluations[item.ID]; ok {
+			evaluations = append(evaluations, evaluation)
+		}
+	}
+	return evaluations, nil
+}
+
+const evaluationChecklist = `# Release Checklist
+
+[[LLM: Be strict.]]
+
+## 1. Docs
+
+- [ ] Changelog lists breaking changes
+- [ ] Upgrade guide exists
+- [x] README mentions the release
+
+## 2. Delivery
+
+[[LLM: Only released artifacts count.]]
+
+- [ ] Binaries are published
+`
+
+func newEvaluationProcessor(t *testing.T, evaluator ChecklistEvaluator) *ChecklistProcessor {
+	t.Helper()
+
+	cp := &ChecklistProcessor{results: map[string]ChecklistItem{}, evaluator: evaluator}
+	if err := cp.parseMarkdownChecklist(evaluationChecklist); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cp.document = "docs/release.md"
+	cp.lines = []string{"# Release 2.0", "", "## Breaking changes", "- The config format changed"}
+	return cp
+}
+
+func TestProcessYolo_RecordsEvaluations(t *testing.T) {
+	evaluator := &stubChecklistEvaluator{evaluations: map[string]ItemEvaluation{
+		"1.1": {ID: "1.1", Status: "PASS", Rationale: "Breaking changes are listed.", Evidence: []int{3, 4, 99}},
+		"1.2": {ID: "1.2", Status: "fail", Rationale: "No upgrade guide."},
+		"2.1": {ID: "2.1", Status: "maybe"},
+	}}
+	cp := newEvaluationProcessor(t, evaluator)
+
+	if err := cp.processYolo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]struct{ status, notes string }{
+		"1.1": {"pass", `Breaking changes are listed. Evidence: L3 "## Breaking changes"; L4 "- The config format changed"`},
+		"1.2": {"fail", "No upgrade guide."},
+		"1.3": {"pass", "Checked in the checklist source"},
+		"2.1": {"pending", `Not evaluated: unknown status "maybe"`},
+	}
+	for id, expected := range want {
+		if got := cp.results[id]; got.Status != expected.status || got.Notes != expected.notes {
+			t.Errorf("Expected %s to be %s with notes %q, got %s with %q", id, expected.status, expected.notes, got.Status, got.Notes)
+		}
+	}
+
+	if len(evaluator.requests) != 2 {
+		t.Fatalf("Expected one request per section, got %d", len(evaluator.requests))
+	}
+	first, second := evaluator.requests[0], evaluator.requests[1]
+	if strings.Contains(first.Prompt, "README mentions the release") {
+		t.Error("Expected items checked in the source to be left out of the prompt")
+	}
+	for _, want := range []string{"System guidance:\nBe strict.", "[1.1] Changelog lists breaking changes", "3: ## Breaking changes"} {
+		if !strings.Contains(first.Prompt, want) {
+			t.Errorf("Expected %q in the prompt:\n%s", want, first.Prompt)
+		}
+	}
+	if second.Guidance != "Be strict.\n\nOnly released artifacts count." || second.Document != "docs/release.md" {
+		t.Errorf("Unexpected second request: %+v", second)
+	}
+}
+
+func TestProcessYolo_WithoutEvaluatorLeavesItemsPending(t *testing.T) {
+	cp := newEvaluationProcessor(t, nil)
+
+	if err := cp.processYolo(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cp.results["1.1"]; got.Status != "pending" || !strings.HasPrefix(got.Notes, "Not evaluated") {
+		t.Errorf("Expected 1.1 to stay pending, got %+v", got)
+	}
+	if got := cp.results["1.3"]; got.Status != "pass" {
+		t.Errorf("Expected 1.3 to keep its checked status, got %+v", got)
+	}
+}
+
+func TestAgentChecklistEvaluator_SendsPromptOnStdin(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+	evaluator := &agentChecklistEvaluator{
+		runner:  runner,
+		ctx:     context.Background(),
+		step:    WorkflowStep{Agent: "qa", Task: "/execute-checklist", Prompt: "Validate the PRD"},
+		stepNum: 2,
+	}
+
+	// The fake agent echoes its stdin, so the reply carries the prompt's JSON
+	document := strings.Repeat("A line of the target document\n", 6000)
+	prompt := document + `[{"id": "1.1", "status": "pass", "rationale": "Listed."}]`
+	evaluations, err := evaluator.Evaluate(EvaluationRequest{Prompt: prompt})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(evaluations) != 1 || evaluations[0].ID != "1.1" || evaluations[0].Status != "pass" {
+		t.Errorf("Unexpected evaluations: %+v", evaluations)
+	}
+}
+
+func TestParseItemEvaluations(t *testing.T) {
+	reply := "Here is my evaluation:\n```json\n[{\"id\": \"1.1\", \"status\": \"partial\", \"rationale\": \"Only some [listed] changes.\", \"evidence\": [2]}]\n```\n"
+
+	evaluations, err := parseItemEvaluations(reply)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(evaluations) != 1 || evaluations[0].Status != "partial" || evaluations[0].Evidence[0] != 2 {
+		t.Errorf("Unexpected evaluations: %+v", evaluations)
+	}
+
+	if _, err := parseItemEvaluations("I could not evaluate the document."); ErrorClassOf(err) != ErrorClassAgent {
+		t.Errorf("Expected an agent error, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Failure policies controlling what happens to the rest of a workflow when a step fails
+const (
+	// FailurePolicyFailFast aborts the workflow on the first failed step (default)
+	FailurePolicyFailFast = "fail-fast"
+	// FailurePolicyContinue keeps running every remaining step, including dependents of failures
+	FailurePolicyContinue = "continue"
+	// FailurePolicySkipDependents finishes independent branches and skips descendants of failures
+	FailurePolicySkipDependents = "skip-dependents"
+)
+
+// validateFailurePolicy checks a configured failure_policy value
+func validateFailurePolicy(policy string) error {
+	switch policy {
+	case "", FailurePolicyFailFast, FailurePolicyContinue, FailurePolicySkipDependents:
+		return nil
+	default:
+		return NewStepError(ErrorClassWorkflowValidation, nil, "unknown failure_policy %q (expected %s, %s or %s)",
+			policy, FailurePolicyFailFast, FailurePolicyContinue, FailurePolicySkipDependents)
+	}
+}
+
+// failurePolicy returns the configured failure policy, defaulting to fail-fast
+func (pe *ParallelExecutor) failurePolicy() string {
+	if pe.config.FailurePolicy == "" {
+		return FailurePolicyFailFast
+	}
+	return pe.config.FailurePolicy
+}
+
+// PartialFailureError reports a run that finished under a continue or
+// skip-dependents policy with some steps failed or skipped
+type PartialFailureError struct {
+	Failed    []int
+	Skipped   []int
+	Succeeded int
+	Total     int
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("workflow finished with failures: %d succeeded, %d failed, %d skipped of %d steps",
+		e.Succeeded, len(e.Failed), len(e.Skipped), e.Total)
+}
+
+// markDescendantsSkipped records every transitive dependent of a failed step as blocked
+func (pe *ParallelExecutor) markDescendantsSkipped(graph *DependencyGraph, failed int, blocked map[int]string) {
+	reason := fmt.Sprintf("depends on failed step %d (%s)", failed+1, graph.Steps[failed].ID)
+
+	queue := append([]int{}, graph.AdjacencyList[failed]...)
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if _, exists := blocked[node]; exists {
+			continue
+		}
+		blocked[node] = reason
+		queue = append(queue, graph.AdjacencyList[node]...)
+	}
+}
+
+// skipStep records a step as skipped without executing it. Steps blocked by a
+// failure are unsuccessful; steps that were not needed, such as bypassed
+// remediation steps, are successful.
+func (pe *ParallelExecutor) skipStep(stepIndex int, reason string, success bool) *StepResult {
+	now := time.Now()
+	result := &StepResult{
+		StepIndex:  stepIndex,
+		Success:    success,
+		Skipped:    true,
+		SkipReason: reason,
+		StartTime:  now,
+		EndTime:    now,
+	}
+
+	pe.mutex.Lock()
+	pe.stepResults[stepIndex] = result
+	pe.mutex.Unlock()
+
+	pe.updateProgress(stepIndex, -1, "skipped", fmt.Sprintf("Step %d skipped: %s", stepIndex+1, reason))
+
+	if pe.onStepComplete != nil {
+		pe.onStepComplete(result)
+	}
+
+	return result
+}
+
+// runOutcome summarizes the recorded results, returning a PartialFailureError
+// when any step failed or was skipped
+func (pe *ParallelExecutor) runOutcome(totalSteps int) error {
+	pe.mutex.RLock()
+	defer pe.mutex.RUnlock()
+
+	outcome := &PartialFailureError{Total: totalSteps}
+	for stepIndex, result := range pe.stepResults {
+		switch {
+		case result.Success:
+			outcome.Succeeded++
+		case result.Skipped:
+			outcome.Skipped = append(outcome.Skipped, stepIndex)
+		default:
+			outcome.Failed = append(outcome.Failed, stepIndex)
+		}
+	}
+
+	if len(outcome.Failed) == 0 && len(outcome.Skipped) == 0 {
+		return nil
+	}
+
+	sort.Ints(outcome.Failed)
+	sort.Ints(outcome.Skipped)
+	return outcome
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failurePolicySteps is a workflow with two independent branches:
+// design -> build -> review and requirements -> docs
+func failurePolicySteps() []WorkflowStep {
+	return []WorkflowStep{
+		{ID: "design"},
+		{ID: "build", DependsOn: []string{"design"}},
+		{ID: "review", DependsOn: []string{"build"}},
+		{ID: "requirements"},
+		{ID: "docs", DependsOn: []string{"requirements"}},
+	}
+}
+
+// runWithFailurePolicy executes failurePolicySteps with "design" failing and
+// returns the ids of the steps that actually ran and the execution error
+func runWithFailurePolicy(t *testing.T, policy string, enableParallel bool) (*ParallelExecutor, map[string]bool, error) {
+	t.Helper()
+
+	config := DefaultParallelConfig()
+	config.EnableParallel = enableParallel
+	config.FailurePolicy = policy
+	executor := NewParallelExecutor(config)
+	t.Cleanup(executor.Cleanup)
+
+	var mu sync.Mutex
+	ran := make(map[string]bool)
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			mu.Lock()
+			ran[step.ID] = true
+			mu.Unlock()
+
+			if step.ID == "design" {
+				return fmt.Errorf("architect agent failed")
+			}
+			return nil
+		},
+	}
+
+	err := executor.ExecuteParallel(mockEngine, failurePolicySteps())
+	return executor, ran, err
+}
+
+func TestFailurePolicy_SkipDependents(t *testing.T) {
+	for _, enableParallel := range []bool{true, false} {
+		t.Run(fmt.Sprintf("parallel=%t", enableParallel), func(t *testing.T) {
+			executor, ran, err := runWithFailurePolicy(t, FailurePolicySkipDependents, enableParallel)
+
+			var partial *PartialFailureError
+			if !errors.As(err, &partial) {
+				t.Fatalf("Expected PartialFailureError, got %v", err)
+			}
+
+			if partial.Succeeded != 2 || len(partial.Failed) != 1 || len(partial.Skipped) != 2 {
+				t.Errorf("Unexpected outcome: %+v", partial)
+			}
+
+			if ran["build"] || ran["review"] {
+				t.Error("Descendants of the failed step should not run")
+			}
+
+			if !ran["requirements"] || !ran["docs"] {
+				t.Error("Independent branch should finish")
+			}
+
+			results := executor.GetResults()
+			if !results[2].Skipped || !strings.Contains(results[2].SkipReason, "design") {
+				t.Errorf("Expected transitive skip reason naming the failed step, got %+v", results[2])
+			}
+
+			if exitCodeForError(err) != exitPartialSuccess {
+				t.Errorf("Expected partial success exit code, got %d", exitCodeForError(err))
+			}
+
+			executor.PrintExecutionSummary()
+		})
+	}
+}
+
+func TestFailurePolicy_Continue(t *testing.T) {
+	_, ran, err := runWithFailurePolicy(t, FailurePolicyContinue, true)
+
+	var partial *PartialFailureError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected PartialFailureError, got %v", err)
+	}
+
+	if len(ran) != 5 {
+		t.Errorf("Expected every step to run under continue, ran %v", ran)
+	}
+
+	if partial.Succeeded != 4 || len(partial.Failed) != 1 || len(partial.Skipped) != 0 {
+		t.Errorf("Unexpected outcome: %+v", partial)
+	}
+}
+
+func TestFailurePolicy_FailFast(t *testing.T) {
+	_, ran, err := runWithFailurePolicy(t, "", false)
+
+	if err == nil {
+		t.Fatal("Expected fail-fast error")
+	}
+
+	var partial *PartialFailureError
+	if errors.As(err, &partial) {
+		t.Errorf("Fail-fast should not report partial success: %v", err)
+	}
+
+	if ran["build"] || ran["requirements"] {
+		t.Errorf("Fail-fast should stop at the first failure, ran %v", ran)
+	}
+
+	if exitCodeForError(err) != exitFailure {
+		t.Errorf("Expected failure exit code, got %d", exitCodeForError(err))
+	}
+}
+
+func TestFailurePolicy_FailFastWaitsForRunningSteps(t *testing.T) {
+	config := DefaultParallelConfig()
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	// design only fails once requirements is running, so the failure always
+	// finds a step in flight
+	var mu sync.Mutex
+	finished := false
+	started := make(chan struct{})
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			if step.ID == "design" {
+				<-started
+				return fmt.Errorf("architect agent failed")
+			}
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			finished = true
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	steps := []WorkflowStep{{ID: "design"}, {ID: "requirements"}}
+	if err := executor.ExecuteParallel(mockEngine, steps); err == nil {
+		t.Fatal("Expected fail-fast error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !finished {
+		t.Error("Expected fail-fast to wait for the steps still running")
+	}
+}
+
+func TestValidateFailurePolicy(t *testing.T) {
+	for _, policy := range []string{"", FailurePolicyFailFast, FailurePolicyContinue, FailurePolicySkipDependents} {
+		if err := validateFailurePolicy(policy); err != nil {
+			t.Errorf("Policy %q should be valid: %v", policy, err)
+		}
+	}
+
+	if err := validateFailurePolicy("ignore"); err == nil {
+		t.Error("Expected error for unknown failure policy")
+	}
+}
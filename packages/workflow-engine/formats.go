@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateKeys are the top-level template keys that are not part of a
+// structured (yaml/json) output schema
+var templateKeys = map[string]bool{"template": true, "workflow": true, "sections": true}
+
+// enumCommentPattern matches allowed values in a schema comment, e.g.
+// `# PASS|CONCERNS|FAIL|WAIVED` or `# Use fixed severity: low | medium | high`
+var enumCommentPattern = regexp.MustCompile(`(?:([A-Za-z_]+):\s*)?([A-Za-z_]+(?:\s*\|\s*[A-Za-z_]+)+)`)
+
+// TemplateFormat renders a parsed template into the processor's output lines
+type TemplateFormat interface {
+	Render(dp *DocumentProcessor, template Template, mode string) error
+}
+
+// templateFormats maps output.format values to their renderers
+var templateFormats = map[string]TemplateFormat{
+	"markdown": markdownFormat{},
+	"yaml":     structuredFormat{encode: encodeYAMLSchema},
+	"json":     structuredFormat{encode: encodeJSONSchema},
+}
+
+// templateFormatFor returns the renderer for an output format (default markdown)
+func templateFormatFor(format string) (TemplateFormat, error) {
+	if format == "" {
+		format = "markdown"
+	}
+	renderer, ok := templateFormats[strings.ToLower(format)]
+	if !ok {
+		return nil, NewStepError(ErrorClassTemplate, nil, "unsupported template output format %q", format).
+			WithRemediation("Use output.format markdown, yaml or json")
+	}
+	return renderer, nil
+}
+
+// parseTemplate parses a template file, keeping the top-level keys outside
+// template/workflow/sections as the schema for structured output formats
+func parseTemplate(data []byte) (Template, error) {
+	var template Template
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return template, NewStepError(ErrorClassTemplate, err, "error parsing template YAML")
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return template, NewStepError(ErrorClassTemplate, err, "error parsing template YAML")
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return template, nil
+	}
+
+	root := document.Content[0]
+	schema := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+		if templateKeys[key] || isGuidanceKey(key) {
+			continue
+		}
+		schema.Content = append(schema.Content, root.Content[i], root.Content[i+1])
+	}
+	if len(schema.Content) > 0 {
+		template.schema = schema
+	}
+	return template, nil
+}
+
+// isGuidanceKey reports whether a schema key only holds examples for authors
+func isGuidanceKey(key string) bool {
+	return key == "examples" || strings.HasSuffix(key, "_examples")
+}
+
+// markdownFormat renders the title and sections as a markdown document
+type markdownFormat struct{}
+
+func (markdownFormat) Render(dp *DocumentProcessor, template Template, mode string) error {
+	dp.addToOutput("# " + template.Template.Output.Title)
+	dp.addToOutput("")
+
+	if mode == "yolo" {
+		return dp.processSectionsYolo(template.Sections, 0)
+	}
+	return dp.processSectionsInteractive(template.Sections, 0)
+}
+
+// structuredFormat fills a template's schema from variables and answers and
+// encodes it, e.g. as the YAML of a QA gate file
+type structuredFormat struct {
+	encode func(schema *yaml.Node) (string, error)
+}
+
+func (f structuredFormat) Render(dp *DocumentProcessor, template Template, mode string) error {
+	if template.schema == nil {
+		return NewStepError(ErrorClassTemplate, nil, "template %q has output format %s but no schema fields",
+			template.Template.ID, template.Template.Output.Format)
+	}
+
+	filler := &schemaFiller{dp: dp, interactive: mode != "yolo", layer: map[string]interface{}{}}
+	schema := copyNode(template.schema)
+	if err := filler.fillMapping(schema, "", nil); err != nil {
+		return err
+	}
+
+	content, err := f.encode(schema)
+	if err != nil {
+		return NewStepError(ErrorClassTemplate, err, "error encoding %s output", template.Template.Output.Format)
+	}
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		dp.addToOutput(line)
+	}
+	dp.addToOutput("")
+	return nil
+}
+
+// enumRule restricts a field to a set of values. Rules without a field apply
+// to the key they annotate; named rules apply to that field anywhere below it.
+type enumRule struct {
+	field  string
+	values []string
+}
+
+// enumRules extracts allowed-value rules from the comments on a key/value pair
+func enumRules(key, value *yaml.Node) []enumRule {
+	var rules []enumRule
+	for _, comment := range []string{key.HeadComment, key.LineComment, value.LineComment} {
+		for _, match := range enumCommentPattern.FindAllStringSubmatch(comment, -1) {
+			rule := enumRule{field: match[1]}
+			for _, option := range strings.Split(match[2], "|") {
+				rule.values = append(rule.values, strings.TrimSpace(option))
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// match returns the allowed value equal to value, ignoring case
+func (r enumRule) match(value string) (string, bool) {
+	for _, allowed := range r.values {
+		if strings.EqualFold(allowed, value) {
+			return allowed, true
+		}
+	}
+	return "", false
+}
+
+// schemaFiller fills {{var}} placeholders in a schema and enforces enum comments.
+// Values the user types in interactive mode are kept in layer so each
+// placeholder is asked for once.
+type schemaFiller struct {
+	dp          *DocumentProcessor
+	interactive bool
+	layer       map[string]interface{}
+}
+
+func (f *schemaFiller) scope() *VariableScope {
+	return f.dp.variableScope().with(f.layer)
+}
+
+// fillMapping fills every value of a mapping; inherited holds named enum rules from ancestors
+func (f *schemaFiller) fillMapping(node *yaml.Node, path string, inherited []enumRule) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldPath := key.Value
+		if path != "" {
+			fieldPath = path + "." + key.Value
+		}
+
+		var enum *enumRule
+		rules := append([]enumRule(nil), inherited...)
+		for _, rule := range enumRules(key, value) {
+			rule := rule
+			if rule.field == "" || rule.field == key.Value {
+				enum = &rule
+			} else {
+				rules = append(rules, rule)
+			}
+		}
+		for _, rule := range inherited {
+			rule := rule
+			if rule.field == key.Value {
+				enum = &rule
+			}
+		}
+
+		if err := f.fillValue(value, fieldPath, enum, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillValue fills one schema value. Empty collections are replaced by a
+// variable named after the field's dotted path when one is defined.
+func (f *schemaFiller) fillValue(node *yaml.Node, path string, enum *enumRule, rules []enumRule) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return f.fillScalar(node, path, enum, rules)
+	case yaml.MappingNode, yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			if value, ok := f.scope().Lookup(path); ok {
+				if err := node.Encode(value); err != nil {
+					return NewStepError(ErrorClassUserInput, err, "invalid value for %s", path)
+				}
+			}
+		}
+		if node.Kind == yaml.MappingNode {
+			return f.fillMapping(node, path, rules)
+		}
+		for _, item := range node.Content {
+			if err := f.fillValue(item, path, nil, rules); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillScalar interpolates a scalar, prompting for undefined placeholders in
+// interactive mode, and checks it against its enum rule
+func (f *schemaFiller) fillScalar(node *yaml.Node, path string, enum *enumRule, rules []enumRule) error {
+	names := placeholderNames(node.Value)
+	whole := len(names) == 1 && strings.TrimSpace(variablePattern.ReplaceAllString(node.Value, "")) == ""
+
+	for _, name := range names {
+		if _, defined := f.scope().Lookup(name); defined || !f.interactive {
+			continue
+		}
+		var rule *enumRule
+		if whole {
+			rule = enum
+		}
+		value, err := f.prompt(name, rule)
+		if err != nil {
+			return err
+		}
+		f.layer[name] = value
+	}
+
+	// A lone placeholder bound to a list or map is replaced by that structure
+	if whole {
+		if value, ok := f.scope().Lookup(names[0]); ok {
+			switch value.(type) {
+			case []interface{}, map[string]interface{}:
+				if err := node.Encode(value); err != nil {
+					return NewStepError(ErrorClassUserInput, err, "invalid value for %s", path)
+				}
+				return f.fillValue(node, path, nil, rules)
+			}
+		}
+	}
+
+	value, err := f.scope().Interpolate(node.Value)
+	if err != nil {
+		return err
+	}
+	node.Value = value
+
+	if enum == nil || variablePattern.MatchString(value) {
+		return nil
+	}
+	allowed, ok := enum.match(value)
+	if !ok {
+		return NewStepError(ErrorClassUserInput, nil, "%s: %q is not one of %s", path, value, strings.Join(enum.values, "|")).
+			WithRemediation(fmt.Sprintf("Set %s to one of %s", path, strings.Join(enum.values, ", ")))
+	}
+	node.Value = allowed
+	return nil
+}
+
+// prompt asks for a placeholder value, repeating until it satisfies rule
+func (f *schemaFiller) prompt(name string, rule *enumRule) (string, error) {
+	question := fmt.Sprintf("Enter %s:", name)
+	if rule != nil {
+		question = fmt.Sprintf("Enter %s (%s):", name, strings.Join(rule.values, "|"))
+	}
+
+	for {
+		value, err := f.dp.getUserInput(question)
+		if err != nil {
+			return "", err
+		}
+		if rule == nil {
+			return value, nil
+		}
+		if allowed, ok := rule.match(value); ok {
+			return allowed, nil
+		}
+		fmt.Printf("   Please enter one of %s\n", strings.Join(rule.values, ", "))
+	}
+}
+
+// copyNode returns a deep copy of a YAML node
+func copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = copyNode(child)
+	}
+	return &copied
+}
+
+// encodeYAMLSchema encodes a filled schema as YAML, keeping its comments
+func encodeYAMLSchema(schema *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(schema); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// encodeJSONSchema encodes a filled schema as indented JSON, keeping key order
+func encodeJSONSchema(schema *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, schema); err != nil {
+		return "", err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return "", err
+	}
+	return indented.String(), nil
+}
+
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSONNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSONNode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const gateTemplateYAML = `template:
+  id: qa-gate
+  name: Quality Gate Decision
+  version: 1.0
+  output:
+    format: yaml
+    filename: gates/{{epic_num}}.{{story_num}}-{{story_slug}}.yml
+    title: "Quality Gate: {{epic_num}}.{{story_num}}"
+
+schema: 1
+story: "{{epic_num}}.{{story_num}}"
+gate: "{{gate_status}}" # PASS|CONCERNS|FAIL|WAIVED
+reviewer: "Quinn"
+
+# Issues (if any) - Use fixed severity: low | medium | high
+top_issues: []
+
+examples:
+  with_issues: |
+    top_issues: []
+`
+
+func renderGate(t *testing.T, variables map[string]interface{}, mode, input string) (*DocumentProcessor, error) {
+	t.Helper()
+
+	template, err := parseTemplate([]byte(gateTemplateYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dp := newTestProcessor(false, variables, input)
+	return dp, dp.processTemplate(template, mode)
+}
+
+func TestStructuredFormat_YAMLFromVariables(t *testing.T) {
+	variables := map[string]interface{}{
+		"epic_num":    1,
+		"story_num":   2,
+		"gate_status": "concerns",
+		"top_issues": []interface{}{
+			map[string]interface{}{"id": "SEC-001", "severity": "HIGH", "finding": "No rate limiting"},
+		},
+	}
+
+	dp, err := renderGate(t, variables, "yolo", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var gate struct {
+		Schema    int    `yaml:"schema"`
+		Story     string `yaml:"story"`
+		Gate      string `yaml:"gate"`
+		TopIssues []struct {
+			ID       string `yaml:"id"`
+			Severity string `yaml:"severity"`
+		} `yaml:"top_issues"`
+		Examples interface{} `yaml:"examples"`
+	}
+	document := strings.Join(dp.output, "\n")
+	if err := yaml.Unmarshal([]byte(document), &gate); err != nil {
+		t.Fatalf("Output is not valid YAML: %v\n%s", err, document)
+	}
+
+	if gate.Schema != 1 || gate.Story != "1.2" {
+		t.Errorf("Unexpected required fields: %+v", gate)
+	}
+	if gate.Gate != "CONCERNS" {
+		t.Errorf("Expected the enum value normalized to CONCERNS, got %q", gate.Gate)
+	}
+	if len(gate.TopIssues) != 1 || gate.TopIssues[0].Severity != "high" {
+		t.Errorf("Expected top_issues filled from the list variable, got %+v", gate.TopIssues)
+	}
+	if gate.Examples != nil {
+		t.Error("Guidance examples should not be part of the output")
+	}
+	if !strings.Contains(document, "# PASS|CONCERNS|FAIL|WAIVED") {
+		t.Error("Expected schema comments to be kept")
+	}
+}
+
+func TestStructuredFormat_EnumViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables map[string]interface{}
+		field     string
+	}{
+		{"gate", map[string]interface{}{"gate_status": "MAYBE"}, "gate"},
+		{"severity", map[string]interface{}{
+			"gate_status": "FAIL",
+			"top_issues":  []interface{}{map[string]interface{}{"severity": "critical"}},
+		}, "top_issues.severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renderGate(t, tt.variables, "yolo", "")
+			if ErrorClassOf(err) != ErrorClassUserInput || !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("Expected a user-input error for %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestStructuredFormat_InteractivePromptsWithEnum(t *testing.T) {
+	// Placeholders are asked once each; the gate is re-asked until it is valid
+	dp, err := renderGate(t, nil, "interactive", "3\n7\nmaybe\npass\n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	document := strings.Join(dp.output, "\n")
+	if !strings.Contains(document, `story: "3.7"`) || !strings.Contains(document, `gate: "PASS"`) {
+		t.Errorf("Unexpected document:\n%s", document)
+	}
+}
+
+func TestStructuredFormat_JSONKeepsKeyOrder(t *testing.T) {
+	template, err := parseTemplate([]byte(`template:
+  id: gate
+  output:
+    format: json
+    filename: gate.json
+gate: "{{gate_status}}"
+schema: 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dp := newTestProcessor(false, map[string]interface{}{"gate_status": "PASS"}, "")
+	if err := dp.processTemplate(template, "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	document := strings.Join(dp.output, "\n")
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(document), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, document)
+	}
+	if strings.Index(document, `"gate"`) > strings.Index(document, `"schema"`) {
+		t.Errorf("Expected template key order to be kept:\n%s", document)
+	}
+}
+
+func TestExecuteTemplateTask_WritesYAMLToInterpolatedFilename(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "qa-gate-tmpl.yaml")
+	if err := ioutil.WriteFile(templatePath, []byte(gateTemplateYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	answersYAML := "variables:\n  epic_num: 2\n  story_num: 5\n  story_slug: login\n  gate_status: WAIVED\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "answers.yaml"), []byte(answersYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &WorkflowEngine{
+		processor: newTestProcessor(false, nil, ""),
+		paths:     NewPathResolver(templatePath, PathConfig{}, nil, dir),
+	}
+
+	step := WorkflowStep{Template: templatePath, Mode: "yolo", Answers: "answers.yaml"}
+	output, err := engine.executeTemplateTask(step, 1)
+	if err != nil {
+		t.Fatalf("Template task failed: %v", err)
+	}
+
+	if want := filepath.Join(dir, "gates", "2.5-login.yml"); output.File != want {
+		t.Errorf("Expected output at %s, got %s", want, output.File)
+	}
+	data, err := ioutil.ReadFile(output.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `gate: "WAIVED"`) {
+		t.Errorf("Unexpected gate file:\n%s", data)
+	}
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -16,6 +20,8 @@ import (
 
 // WorkflowStep represents a single step in a BMAD workflow
 type WorkflowStep struct {
+	ID        string                 `yaml:"id,omitempty"`
+	DependsOn []string               `yaml:"depends_on,omitempty"`
 	Agent     string                 `yaml:"agent"`
 	Task      string                 `yaml:"task"`
 	Prompt    string                 `yaml:"prompt"`
@@ -23,6 +29,11 @@ type WorkflowStep struct {
 	Checklist string                 `yaml:"checklist,omitempty"`
 	Mode      string                 `yaml:"mode,omitempty"` // interactive, yolo
 	Variables map[string]interface{} `yaml:"variables,omitempty"`
+	Retry     *RetryPolicy           `yaml:"retry,omitempty"`
+	Outputs   map[string]string      `yaml:"outputs,omitempty"`          // output name -> source, e.g. file, stdout, json:summary
+	Answers   string                 `yaml:"answers,omitempty"`          // answers file for unattended runs
+	Examples  bool                   `yaml:"include_examples,omitempty"` // emit template examples as comments in yolo drafts
+	Threshold *ChecklistThreshold    `yaml:"threshold,omitempty"`        // pass criteria for checklist steps
 }
 
 // Workflow represents a BMAD workflow configuration
@@ -31,23 +42,58 @@ type Workflow struct {
 	Description string                  `yaml:"description"`
 	Steps       []WorkflowStep          `yaml:"steps"`
 	Variables   map[string]interface{}  `yaml:"variables,omitempty"`
+	Strict      bool                    `yaml:"strict_variables,omitempty"` // fail on undefined {{var}} placeholders
+	Paths       PathConfig              `yaml:"paths,omitempty"`
+	Answers     string                  `yaml:"answers,omitempty"` // default answers file for every step
 	Parallel    ParallelExecutionConfig `yaml:"parallel,omitempty"`
+	OpenCode    OpenCodeConfig          `yaml:"opencode,omitempty"`
 }
 
 // Template structures for BMAD templates
 type TemplateSection struct {
-	ID          string              `yaml:"id"`
-	Title       string              `yaml:"title"`
-	Instruction string              `yaml:"instruction"`
-	Elicit      bool                `yaml:"elicit,omitempty"`
-	Sections    []TemplateSection   `yaml:"sections,omitempty"`
-	Type        string              `yaml:"type,omitempty"`
-	Prefix      string              `yaml:"prefix,omitempty"`
-	Columns     []string            `yaml:"columns,omitempty"`
-	Examples    []string            `yaml:"examples,omitempty"`
-	Repeatable  bool                `yaml:"repeatable,omitempty"`
-	Template    string              `yaml:"template,omitempty"`
-	Choices     map[string][]string `yaml:"choices,omitempty"`
+	ID           string            `yaml:"id"`
+	Title        string            `yaml:"title"`
+	Instruction  string            `yaml:"instruction"`
+	Elicit       bool              `yaml:"elicit,omitempty"`
+	Sections     []TemplateSection `yaml:"sections,omitempty"`
+	Type         string            `yaml:"type,omitempty"`
+	Prefix       string            `yaml:"prefix,omitempty"`
+	Columns      []string          `yaml:"columns,omitempty"`
+	Rows         []TableRow        `yaml:"rows,omitempty"`
+	Examples     []string          `yaml:"examples,omitempty"`
+	Repeatable   bool              `yaml:"repeatable,omitempty"`
+	Template     string            `yaml:"template,omitempty"`
+	ItemTemplate string            `yaml:"item_template,omitempty"` // format of each item of a list section
+	Choices      TemplateChoices   `yaml:"choices,omitempty"`
+	MultiSelect  bool              `yaml:"multi_select,omitempty"`
+	MermaidType  string            `yaml:"mermaid_type,omitempty"`
+	Language     string            `yaml:"language,omitempty"`
+	Elicitation  *ElicitationMenu  `yaml:"custom_elicitation,omitempty"`
+	Condition    string            `yaml:"condition,omitempty"`
+}
+
+// TemplateChoices maps a choice name to its options. Templates may also give a
+// plain list (`choices: [Draft, Approved]`), stored under the empty name as the
+// section's own choice.
+type TemplateChoices map[string][]string
+
+// UnmarshalYAML accepts both the map and the plain list form
+func (c *TemplateChoices) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var options []string
+		if err := node.Decode(&options); err != nil {
+			return err
+		}
+		*c = TemplateChoices{"": options}
+		return nil
+	}
+
+	var choices map[string][]string
+	if err := node.Decode(&choices); err != nil {
+		return err
+	}
+	*c = choices
+	return nil
 }
 
 type TemplateConfig struct {
@@ -62,21 +108,58 @@ type TemplateConfig struct {
 }
 
 type TemplateWorkflow struct {
-	Mode        string `yaml:"mode"`
-	Elicitation string `yaml:"elicitation"`
+	Mode              string           `yaml:"mode"`
+	Elicitation       string           `yaml:"elicitation"`
+	CustomElicitation *ElicitationMenu `yaml:"custom_elicitation,omitempty"`
 }
 
 type Template struct {
 	Template TemplateConfig    `yaml:"template"`
 	Workflow TemplateWorkflow  `yaml:"workflow"`
 	Sections []TemplateSection `yaml:"sections"`
+
+	// schema holds the remaining top-level keys, the body of yaml/json outputs
+	schema *yaml.Node
 }
 
 // DocumentProcessor handles template processing and output generation
 type DocumentProcessor struct {
-	variables map[string]interface{}
-	output    []string
-	reader    *bufio.Reader
+	output     []string
+	reader     *bufio.Reader
+	ctx        context.Context // cancels prompts and processing; nil never cancels
+	scope      *VariableScope
+	paths      *PathResolver
+	conditions *ConditionEvaluator
+	skipped    []SkippedSection
+	choices    []choiceAnswer
+	answers    map[string]interface{}       // choices and placeholder values entered in the step
+	onAnswer   func(map[string]interface{}) // checkpoints answers as they are entered
+	instances  int                          // depth of repeatable instances being rendered
+	examples   bool                         // write section examples into yolo drafts as guidance comments
+	content    ContentProvider              // drafts yolo sections and runs elicitation methods
+	title      string
+	methods    []ElicitationMethod
+	menu       *ElicitationMenu // the template's custom_elicitation
+	transcript []string
+}
+
+// TemplateStepOutput is the output recorded for a template-based step
+type TemplateStepOutput struct {
+	Source          string           `json:"source"`
+	File            string           `json:"file"`
+	Document        []string         `json:"document"`
+	SkippedSections []SkippedSection `json:"skipped_sections,omitempty"`
+	Transcript      string           `json:"transcript,omitempty"`
+}
+
+// ChecklistStepOutput is the output recorded for a checklist-based step
+type ChecklistStepOutput struct {
+	Source   string                   `json:"source"`
+	Report   string                   `json:"report"`
+	Results  map[string]ChecklistItem `json:"results"`
+	PassRate float64                  `json:"pass_rate"`
+	Blockers []string                 `json:"blocker_failures,omitempty"` // ids of failed blocker items
+	Passed   bool                     `json:"passed"`                     // the step's threshold, if any, was met
 }
 
 // WorkflowEngine manages workflow execution state
@@ -85,6 +168,14 @@ type WorkflowEngine struct {
 	processor          *DocumentProcessor
 	checklistProcessor *ChecklistProcessor
 	parallelExecutor   *ParallelExecutor
+	opencode           *OpenCodeRunner
+	cliVariables       map[string]interface{}
+	workflowVariables  map[string]interface{}
+	strictVariables    bool
+	paths              *PathResolver
+	answersFile        string
+	resumedAnswers     map[string]map[string]interface{}                 // answers of unfinished steps, by step id
+	onAnswers          func(step string, answers map[string]interface{}) // checkpoints a step's answers
 }
 
 // Checklist structures
@@ -96,18 +187,22 @@ type ChecklistItem struct {
 	Severity string `yaml:"severity"`         // blocker, high, medium, low
 	Status   string `yaml:"status,omitempty"` // pass, fail, partial, n/a
 	Notes    string `yaml:"notes,omitempty"`
+	Guidance string `yaml:"guidance,omitempty"` // [[LLM: ...]] instructions for this item
 }
 
 type ChecklistSection struct {
-	ID    string          `yaml:"id"`
-	Title string          `yaml:"title"`
-	Items []ChecklistItem `yaml:"items"`
+	ID               string            `yaml:"id"`
+	Title            string            `yaml:"title"`
+	Guidance         string            `yaml:"guidance,omitempty"`
+	CategoryGuidance map[string]string `yaml:"category_guidance,omitempty"` // category -> guidance
+	Items            []ChecklistItem   `yaml:"items"`
 }
 
 type Checklist struct {
 	ID       string             `yaml:"id"`
 	Name     string             `yaml:"name"`
 	Version  string             `yaml:"version"`
+	Guidance string             `yaml:"guidance,omitempty"`
 	Sections []ChecklistSection `yaml:"sections"`
 }
 
@@ -116,6 +211,10 @@ type ChecklistProcessor struct {
 	checklist Checklist
 	results   map[string]ChecklistItem
 	reader    *bufio.Reader
+	ctx       context.Context    // cancels prompts; nil never cancels
+	evaluator ChecklistEvaluator // evaluates yolo runs; nil leaves items pending
+	document  string             // target document path
+	lines     []string           // target document lines
 }
 
 func main() {
@@ -123,14 +222,111 @@ func main() {
 	fmt.Println("   Parallel execution with advanced error handling and external integrations")
 
 	if len(os.Args) < 2 {
-		fmt.Println("\nUsage: workflow-engine <workflow-file.yaml>")
-		fmt.Printf("Example: workflow-engine ./workflows/create-doc.yaml\n")
-		fmt.Printf("Example: workflow-engine ./workflows/execute-checklist.yaml\n")
+		printUsage()
+		os.Exit(1)
+	}
+
+	command, args := "run", os.Args[1:]
+	switch os.Args[1] {
+	case "resume", "validate":
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	opts, err := parseRunOptions(args)
+	if err != nil || len(opts.args) != 1 {
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+		}
+		printUsage()
 		os.Exit(1)
 	}
 
-	workflowFile := os.Args[1]
+	switch command {
+	case "resume":
+		resumeWorkflow(opts.args[0], opts)
+	case "validate":
+		runValidate(opts.args[0], opts)
+	default:
+		runWorkflow(opts.args[0], opts)
+	}
+}
+
+func printUsage() {
+	fmt.Println("\nUsage: workflow-engine [options] <workflow-file.yaml>")
+	fmt.Println("       workflow-engine resume [options] <run-dir>")
+	fmt.Println("       workflow-engine validate [--search-root dir]... <workflow-file.yaml>")
+	fmt.Println("Options: --var name=value (repeatable), --strict, --search-root dir (repeatable), --project-root dir")
+	fmt.Printf("Example: workflow-engine ./workflows/create-doc.yaml\n")
+	fmt.Printf("Example: workflow-engine --var project_name=Acme ./workflows/execute-checklist.yaml\n")
+	fmt.Printf("Example: workflow-engine validate ./workflows/test-parallel.yaml\n")
+	fmt.Printf("Example: workflow-engine resume %s/complex-multi-step-bmad-workflow-20250101-120000\n", defaultRunsDir)
+}
+
+// runOptions holds the command-line flags shared by run and resume
+type runOptions struct {
+	variables   map[string]interface{}
+	strict      bool
+	searchRoots []string
+	projectRoot string
+	answers     map[string]map[string]interface{} // answers of unfinished steps restored from a checkpoint
+	args        []string
+}
+
+// variableFlags collects repeated --var name=value flags
+type variableFlags map[string]interface{}
+
+func (v variableFlags) String() string {
+	return fmt.Sprintf("%v", map[string]interface{}(v))
+}
+
+func (v variableFlags) Set(arg string) error {
+	name, value, err := parseVariableAssignment(arg)
+	if err != nil {
+		return err
+	}
+	v[name] = value
+	return nil
+}
+
+// stringList collects a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
 
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseRunOptions parses flags and positional arguments, which may be interleaved
+func parseRunOptions(args []string) (runOptions, error) {
+	opts := runOptions{variables: make(map[string]interface{})}
+
+	flags := flag.NewFlagSet("workflow-engine", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.Var(variableFlags(opts.variables), "var", "set a workflow variable (name=value); repeatable")
+	flags.BoolVar(&opts.strict, "strict", false, "fail on undefined {{var}} placeholders")
+	flags.Var((*stringList)(&opts.searchRoots), "search-root", "extra directory to search for templates and checklists; repeatable")
+	flags.StringVar(&opts.projectRoot, "project-root", "", "directory generated files are written under")
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return opts, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		opts.args = append(opts.args, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	return opts, nil
+}
+
+// runWorkflow starts a fresh run of a workflow file
+func runWorkflow(workflowFile string, opts runOptions) {
 	// Resolve absolute path
 	absPath, err := filepath.Abs(workflowFile)
 	if err != nil {
@@ -139,18 +335,71 @@ func main() {
 
 	fmt.Printf("\n📁 Loading workflow: %s\n", absPath)
 
-	// Read workflow file
-	data, err := ioutil.ReadFile(absPath)
+	workflow, err := loadWorkflow(absPath)
 	if err != nil {
-		log.Fatalf("❌ Error reading workflow file: %v", err)
+		log.Printf("❌ %v", err)
+		os.Exit(exitCodeForError(err))
 	}
 
-	// Parse YAML
-	var workflow Workflow
-	if err := yaml.Unmarshal(data, &workflow); err != nil {
-		log.Fatalf("❌ Error parsing YAML: %v", err)
+	checkpointer, err := NewCheckpointer(newRunDir(defaultRunsDir, workflow.Name, time.Now()), absPath, workflow.Steps)
+	if err != nil {
+		log.Fatalf("❌ Error initializing checkpoint: %v", err)
 	}
 
+	executeWorkflow(workflow, checkpointer, nil, opts)
+}
+
+// resumeWorkflow continues a checkpointed run from its unfinished steps
+func resumeWorkflow(runDir string, opts runOptions) {
+	fmt.Printf("\n♻️  Resuming run: %s\n", runDir)
+
+	state, err := LoadRunState(runDir)
+	if err != nil {
+		log.Fatalf("❌ Cannot resume: %v", err)
+	}
+
+	fmt.Printf("\n📁 Loading workflow: %s\n", state.WorkflowFile)
+
+	workflow, err := loadWorkflow(state.WorkflowFile)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		os.Exit(exitCodeForError(err))
+	}
+
+	checkpointer, err := ResumeCheckpointer(runDir, workflow.Steps, state)
+	if err != nil {
+		log.Fatalf("❌ Cannot resume: %v", err)
+	}
+
+	results, err := state.Results()
+	if err != nil {
+		log.Fatalf("❌ Cannot resume: %v", err)
+	}
+
+	completed := 0
+	for _, result := range results {
+		if result.Success {
+			completed++
+		}
+	}
+	fmt.Printf("   ✅ %d/%d steps restored from checkpoint\n", completed, len(workflow.Steps))
+
+	// Variables passed to the original run carry over unless overridden again
+	variables := make(map[string]interface{}, len(state.Variables)+len(opts.variables))
+	for name, value := range state.Variables {
+		variables[name] = value
+	}
+	for name, value := range opts.variables {
+		variables[name] = value
+	}
+	opts.variables = variables
+	opts.answers = state.Answers
+
+	executeWorkflow(workflow, checkpointer, results, opts)
+}
+
+// executeWorkflow builds the engine and runs the workflow, checkpointing after each step
+func executeWorkflow(workflow *Workflow, checkpointer *Checkpointer, restored map[int]*StepResult, opts runOptions) {
 	fmt.Printf("📋 Workflow: %s\n", workflow.Name)
 	fmt.Printf("📝 Description: %s\n", workflow.Description)
 	fmt.Printf("🔢 Steps: %d\n", len(workflow.Steps))
@@ -161,26 +410,55 @@ func main() {
 	}
 
 	// Initialize parallel execution configuration
-	parallelConfig := workflow.Parallel
-	if parallelConfig.MaxConcurrency == 0 {
-		parallelConfig = DefaultParallelConfig()
+	parallelConfig := workflow.Parallel.withDefaults()
+
+	variables := opts.variables
+	if variables == nil {
+		variables = make(map[string]interface{})
 	}
 
+	paths := NewPathResolver(checkpointer.WorkflowFile(), workflow.Paths, opts.searchRoots, opts.projectRoot)
+	fmt.Printf("📂 Project root: %s\n", paths.ProjectRoot())
+	fmt.Printf("🔎 Search roots: %s\n", strings.Join(paths.Roots(), ", "))
+
 	// Initialize workflow engine
 	engine := &WorkflowEngine{
 		reader: bufio.NewReader(os.Stdin),
 		processor: &DocumentProcessor{
-			variables: make(map[string]interface{}),
-			output:    []string{},
-			reader:    bufio.NewReader(os.Stdin),
+			output: []string{},
+			reader: bufio.NewReader(os.Stdin),
 		},
 		checklistProcessor: &ChecklistProcessor{
 			results: make(map[string]ChecklistItem),
 			reader:  bufio.NewReader(os.Stdin),
 		},
-		parallelExecutor: NewParallelExecutor(parallelConfig),
+		parallelExecutor:  NewParallelExecutor(parallelConfig),
+		opencode:          NewOpenCodeRunner(workflow.OpenCode),
+		cliVariables:      variables,
+		workflowVariables: workflow.Variables,
+		strictVariables:   opts.strict || workflow.Strict,
+		paths:             paths,
+		answersFile:       workflow.Answers,
+		resumedAnswers:    opts.answers,
 	}
 
+	fmt.Printf("💾 Run directory: %s\n", checkpointer.RunDir())
+	engine.parallelExecutor.RestoreResults(restored)
+	engine.parallelExecutor.onStepComplete = func(result *StepResult) {
+		if err := checkpointer.Record(result, engine.cliVariables); err != nil {
+			fmt.Printf("   ⚠️  Failed to write checkpoint: %v\n", err)
+		}
+	}
+	engine.onAnswers = func(step string, answers map[string]interface{}) {
+		if err := checkpointer.RecordAnswers(step, answers); err != nil {
+			fmt.Printf("   ⚠️  Failed to write checkpoint: %v\n", err)
+		}
+	}
+
+	// Cancel on Ctrl-C so completed steps stay checkpointed for resume
+	stopInterrupts := cancelOnInterrupt(engine.parallelExecutor.cancel)
+	defer stopInterrupts()
+
 	// Execute workflow steps (Epic 3 enhancement - parallel execution)
 	fmt.Printf("\n⚡ Executing %d workflow steps:\n", len(workflow.Steps))
 	fmt.Printf("   🔧 Parallel Mode: %t\n", parallelConfig.EnableParallel)
@@ -190,7 +468,12 @@ func main() {
 
 	// Execute steps using parallel executor
 	if err := engine.parallelExecutor.ExecuteParallel(engine, workflow.Steps); err != nil {
-		log.Fatalf("❌ Error executing workflow: %v", err)
+		engine.parallelExecutor.PrintExecutionSummary()
+		fmt.Printf("\n💾 Progress saved. Resume with: workflow-engine resume %s\n", checkpointer.RunDir())
+		log.Printf("❌ Error executing workflow: %v", err)
+		// os.Exit skips deferred calls
+		engine.parallelExecutor.Cleanup()
+		os.Exit(exitCodeForError(err))
 	}
 
 	// Print execution summary
@@ -204,39 +487,159 @@ func main() {
 	fmt.Printf("   ✅ Real-time progress monitoring and error isolation\n")
 }
 
-func (e *WorkflowEngine) executeStep(step WorkflowStep, stepNum int) error {
+// cancelOnInterrupt calls cancel on the first SIGINT or SIGTERM and restores
+// the default handling, so a second signal exits at once. The returned
+// function stops watching for signals.
+func cancelOnInterrupt(cancel func()) func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+
+	finished := make(chan struct{})
+	go func() {
+		defer signal.Stop(interrupts)
+		select {
+		case <-interrupts:
+			signal.Stop(interrupts)
+			fmt.Printf("\n🛑 Interrupted - cancelling workflow (interrupt again to exit now)\n")
+			cancel()
+		case <-finished:
+		}
+	}()
+
+	return func() { close(finished) }
+}
+
+// Process exit codes
+const (
+	exitFailure        = 1
+	exitPartialSuccess = 2
+	exitInvalid        = 3
+	exitTimeout        = 4
+	exitCancelled      = 130
+)
+
+// exitCodeForError maps a workflow execution error onto the process exit code
+func exitCodeForError(err error) int {
+	var partial *PartialFailureError
+	if errors.As(err, &partial) && partial.Succeeded > 0 {
+		return exitPartialSuccess
+	}
+
+	switch ErrorClassOf(err) {
+	case ErrorClassWorkflowValidation:
+		return exitInvalid
+	case ErrorClassTimeout:
+		return exitTimeout
+	case ErrorClassCancelled:
+		return exitCancelled
+	default:
+		return exitFailure
+	}
+}
+
+// loadWorkflow reads, parses and validates a workflow file
+func loadWorkflow(path string) (*Workflow, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, NewStepError(ErrorClassFilesystem, err, "error reading workflow file")
+	}
+
+	var workflow Workflow
+	if err := yaml.Unmarshal(data, &workflow); err != nil {
+		return nil, NewStepError(ErrorClassWorkflowValidation, err, "error parsing YAML")
+	}
+
+	if err := validateStepReferences(workflow.Steps); err != nil {
+		return nil, wrapStepError(ErrorClassWorkflowValidation, err, "invalid step dependencies")
+	}
+
+	if err := validateFailurePolicy(workflow.Parallel.FailurePolicy); err != nil {
+		return nil, err
+	}
+
+	return &workflow, nil
+}
+
+// runContext returns the context that cancels the run, or nil outside the executor
+func (e *WorkflowEngine) runContext() context.Context {
+	if e.parallelExecutor == nil {
+		return nil
+	}
+	return e.parallelExecutor.ctx
+}
+
+func (e *WorkflowEngine) executeStep(step WorkflowStep, stepNum int) (interface{}, error) {
+	step, err := e.resolveStep(step)
+	if err != nil {
+		return nil, err
+	}
+
 	fmt.Printf("   💬 Prompt: %s\n", step.Prompt)
 
 	// Handle template-based tasks (create-doc)
 	if step.Template != "" {
-		return e.executeTemplateTask(step, stepNum)
+		output, err := e.executeTemplateTask(step, stepNum)
+		if err != nil {
+			return nil, err
+		}
+		return output, nil
 	}
 
 	// Handle checklist-based tasks (execute-checklist)
 	if step.Checklist != "" {
-		return e.executeChecklistTask(step, stepNum)
+		output, err := e.executeChecklistTask(step, stepNum)
+		if err != nil {
+			return nil, err
+		}
+		return output, nil
 	}
 
 	// Handle regular workflow steps
 	return e.executeRegularStep(step, stepNum)
 }
 
-func (e *WorkflowEngine) executeTemplateTask(step WorkflowStep, stepNum int) error {
+func (e *WorkflowEngine) executeTemplateTask(step WorkflowStep, stepNum int) (*TemplateStepOutput, error) {
 	fmt.Printf("   📝 Template-based task: %s\n", step.Template)
 
 	// Load template file
-	templateData, err := ioutil.ReadFile(step.Template)
+	templatePath, err := e.paths.Resolve(step.Template)
+	if err != nil {
+		return nil, wrapStepError(ErrorClassFilesystem, err, "error locating template")
+	}
+	fmt.Printf("   📂 Resolved template: %s\n", templatePath)
+
+	templateData, err := ioutil.ReadFile(templatePath)
 	if err != nil {
-		return fmt.Errorf("error reading template file %s: %v", step.Template, err)
+		return nil, NewStepError(ErrorClassFilesystem, err, "error reading template file %s", templatePath)
 	}
 
-	var template Template
-	if err := yaml.Unmarshal(templateData, &template); err != nil {
-		return fmt.Errorf("error parsing template YAML: %v", err)
+	template, err := parseTemplate(templateData)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := e.loadAnswers(step)
+	if err != nil {
+		return nil, err
+	}
+	scope := e.variableScopeFor(step).withAnswers(answers)
+
+	// Values entered before the step was interrupted are not asked for again
+	id := stepID(step, stepNum-1)
+	if resumed := e.resumedAnswers[id]; len(resumed) > 0 {
+		fmt.Printf("   ♻️  Reusing %d answers from the checkpoint\n", len(resumed))
+		scope = scope.with(resumed)
 	}
 
+	template, err = scope.resolveTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	outputPath := e.paths.ResolveOutput(template.Template.Output.Filename)
+
 	fmt.Printf("   📋 Template: %s (v%s)\n", template.Template.Name, template.Template.Version)
-	fmt.Printf("   📄 Output: %s\n", template.Template.Output.Filename)
+	fmt.Printf("   📄 Output: %s\n", outputPath)
 
 	// Determine execution mode
 	mode := step.Mode
@@ -249,27 +652,90 @@ func (e *WorkflowEngine) executeTemplateTask(step WorkflowStep, stepNum int) err
 
 	fmt.Printf("   🎯 Execution mode: %s\n", mode)
 
+	e.processor.scope = scope
+	e.processor.ctx = e.runContext()
+	e.processor.paths = e.paths
+	e.processor.examples = step.Examples
+	e.processor.onAnswer = nil
+	if e.onAnswers != nil {
+		e.processor.onAnswer = func(answers map[string]interface{}) { e.onAnswers(id, answers) }
+	}
+	e.processor.content = nil
+	if step.Agent != "" && e.opencode != nil {
+		e.processor.content = &agentContentProvider{
+			runner:  e.opencode,
+			ctx:     e.parallelExecutor.ctx,
+			step:    step,
+			stepNum: stepNum,
+		}
+	}
+	e.processor.conditions = &ConditionEvaluator{
+		answers: answers,
+		mode:    mode,
+		confirm: e.processor.askYesNo,
+	}
+
 	// Process template using DocumentProcessor
 	if err := e.processor.processTemplate(template, mode); err != nil {
-		return fmt.Errorf("error processing template: %v", err)
+		return nil, wrapStepError(ErrorClassTemplate, err, "error processing template")
 	}
 
 	// Save output to file
-	if err := e.processor.saveToFile(template.Template.Output.Filename); err != nil {
-		return fmt.Errorf("error saving output file: %v", err)
+	if err := e.processor.saveToFile(outputPath); err != nil {
+		return nil, NewStepError(ErrorClassFilesystem, err, "error saving output file")
+	}
+
+	fmt.Printf("   💾 Output saved to: %s\n", outputPath)
+
+	// Keep the elicitation transcript alongside the document
+	transcript := ""
+	if len(e.processor.transcript) > 0 {
+		if transcript, err = e.processor.saveTranscript(outputPath); err != nil {
+			return nil, NewStepError(ErrorClassFilesystem, err, "error saving elicitation transcript")
+		}
+		fmt.Printf("   🗒️  Elicitation transcript saved to: %s\n", transcript)
 	}
 
-	fmt.Printf("   💾 Output saved to: %s\n", template.Template.Output.Filename)
 	fmt.Printf("   ✅ Template task completed successfully\n")
-	return nil
+	return &TemplateStepOutput{
+		Source:          templatePath,
+		File:            outputPath,
+		Document:        append([]string(nil), e.processor.output...),
+		SkippedSections: append([]SkippedSection(nil), e.processor.skipped...),
+		Transcript:      transcript,
+	}, nil
 }
 
-func (e *WorkflowEngine) executeChecklistTask(step WorkflowStep, stepNum int) error {
+// loadAnswers loads the step's answers file, falling back to the workflow's
+func (e *WorkflowEngine) loadAnswers(step WorkflowStep) (*Answers, error) {
+	file := step.Answers
+	if file == "" {
+		file = e.answersFile
+	}
+	if file == "" {
+		return nil, nil
+	}
+
+	path, err := e.paths.Resolve(file)
+	if err != nil {
+		return nil, wrapStepError(ErrorClassFilesystem, err, "error locating answers file")
+	}
+	fmt.Printf("   📂 Answers file: %s\n", path)
+	return LoadAnswers(path)
+}
+
+func (e *WorkflowEngine) executeChecklistTask(step WorkflowStep, stepNum int) (*ChecklistStepOutput, error) {
 	fmt.Printf("   ☑️  Checklist-based task: %s\n", step.Checklist)
 
 	// Load and parse checklist file
-	if err := e.checklistProcessor.loadChecklist(step.Checklist); err != nil {
-		return fmt.Errorf("error loading checklist: %v", err)
+	checklistPath, err := e.paths.Resolve(step.Checklist)
+	if err != nil {
+		return nil, wrapStepError(ErrorClassFilesystem, err, "error locating checklist")
+	}
+	fmt.Printf("   📂 Resolved checklist: %s\n", checklistPath)
+
+	if err := e.checklistProcessor.loadChecklist(checklistPath); err != nil {
+		return nil, wrapStepError(ErrorClassFilesystem, err, "error loading checklist")
 	}
 
 	fmt.Printf("   📋 Checklist: %s (v%s)\n", e.checklistProcessor.checklist.Name, e.checklistProcessor.checklist.Version)
@@ -282,38 +748,90 @@ func (e *WorkflowEngine) executeChecklistTask(step WorkflowStep, stepNum int) er
 	}
 
 	fmt.Printf("   🎯 Execution mode: %s\n", mode)
+	e.checklistProcessor.ctx = e.runContext()
 
 	// Execute checklist validation
 	if mode == "yolo" {
 		fmt.Printf("   🚀 YOLO mode: Processing entire checklist at once\n")
+		if err := e.prepareEvaluation(step, stepNum); err != nil {
+			return nil, err
+		}
 		if err := e.checklistProcessor.processYolo(); err != nil {
-			return err
+			return nil, wrapStepError(ErrorClassUserInput, err, "error validating checklist")
 		}
 	} else {
 		fmt.Printf("   👤 Interactive mode: Section-by-section validation\n")
 		if err := e.checklistProcessor.processInteractive(); err != nil {
-			return err
+			return nil, wrapStepError(ErrorClassUserInput, err, "error validating checklist")
 		}
 	}
 
 	// Generate and save report
-	reportPath := fmt.Sprintf("docs/checklist-report-%d.md", stepNum)
+	reportPath := e.paths.ResolveOutput(fmt.Sprintf("docs/checklist-report-%d.md", stepNum))
 	if err := e.checklistProcessor.generateReport(reportPath); err != nil {
-		return fmt.Errorf("error generating report: %v", err)
+		return nil, NewStepError(ErrorClassFilesystem, err, "error generating report")
 	}
 
 	fmt.Printf("   📄 Report saved to: %s\n", reportPath)
 	fmt.Printf("   ✅ Checklist validation completed\n")
+
+	results := make(map[string]ChecklistItem, len(e.checklistProcessor.results))
+	for id, item := range e.checklistProcessor.results {
+		results[id] = item
+	}
+	output := &ChecklistStepOutput{Source: checklistPath, Report: reportPath, Results: results}
+	if err := e.applyThreshold(step, output); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// prepareEvaluation loads the step's target_document and sets up the step's
+// agent to evaluate the checklist against it
+func (e *WorkflowEngine) prepareEvaluation(step WorkflowStep, stepNum int) error {
+	cp := e.checklistProcessor
+	cp.evaluator, cp.document, cp.lines = nil, "", nil
+
+	if target, ok := e.variableScopeFor(step).Lookup("target_document"); ok && fmt.Sprint(target) != "" {
+		path, err := e.paths.ResolveDocument(fmt.Sprint(target))
+		if err != nil {
+			return wrapStepError(ErrorClassFilesystem, err, "error locating target document")
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return NewStepError(ErrorClassFilesystem, err, "error reading target document %s", path)
+		}
+		cp.document = path
+		cp.lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		fmt.Printf("   📄 Target document: %s (%d lines)\n", path, len(cp.lines))
+	} else {
+		fmt.Printf("   ⚠️  No target_document variable; the checklist is evaluated against the project\n")
+	}
+
+	if step.Agent != "" && e.opencode != nil {
+		cp.evaluator = &agentChecklistEvaluator{
+			runner:  e.opencode,
+			ctx:     e.parallelExecutor.ctx,
+			step:    step,
+			stepNum: stepNum,
+		}
+	} else {
+		fmt.Printf("   ⚠️  No agent to evaluate the checklist; unchecked items stay pending\n")
+	}
 	return nil
 }
 
-func (e *WorkflowEngine) executeRegularStep(step WorkflowStep, stepNum int) error {
+func (e *WorkflowEngine) executeRegularStep(step WorkflowStep, stepNum int) (*OpenCodeResult, error) {
 	fmt.Printf("   🎯 Regular workflow step\n")
-	fmt.Printf("   Command: opencode run \"@%s %s: %s\"\n", step.Agent, step.Task, step.Prompt)
+	fmt.Printf("   Command: %s %q\n", e.opencode.binary, e.opencode.buildArgs(step))
 
-	// Simulate execution for now
-	fmt.Printf("   ✅ Step executed successfully\n")
-	return nil
+	result, err := e.opencode.Run(e.parallelExecutor.ctx, step, stepNum)
+	if err != nil {
+		return result, wrapStepError(ErrorClassAgent, err, "error running opencode for step %d", stepNum)
+	}
+
+	fmt.Printf("   ✅ Step executed successfully (exit code %d)\n", result.ExitCode)
+	return result, nil
 }
 
 // DocumentProcessor methods for enhanced template processing
@@ -321,94 +839,175 @@ func (e *WorkflowEngine) executeRegularStep(step WorkflowStep, stepNum int) erro
 func (dp *DocumentProcessor) processTemplate(template Template, mode string) error {
 	fmt.Printf("   📝 Processing template: %s\n", template.Template.Name)
 
-	// Initialize document with title
-	dp.addToOutput("# " + template.Template.Output.Title)
-	dp.addToOutput("")
-
-	// Process all sections
-	if mode == "yolo" {
-		return dp.processSectionsYolo(template.Sections, "")
-	} else {
-		return dp.processSectionsInteractive(template.Sections, "")
+	format, err := templateFormatFor(template.Template.Output.Format)
+	if err != nil {
+		return err
 	}
+
+	dp.output = []string{}
+	dp.title = template.Template.Output.Title
+	dp.menu = template.Workflow.CustomElicitation
+	dp.transcript = nil
+	dp.skipped = nil
+	dp.choices = nil
+	dp.answers = map[string]interface{}{}
+	return format.Render(dp, template, mode)
 }
 
-func (dp *DocumentProcessor) processSectionsYolo(sections []TemplateSection, indent string) error {
+func (dp *DocumentProcessor) processSectionsYolo(sections []TemplateSection, depth int) error {
 	for _, section := range sections {
-		if err := dp.processSectionYolo(section, indent); err != nil {
+		if err := dp.processSectionYolo(section, depth); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (dp *DocumentProcessor) processSectionsInteractive(sections []TemplateSection, indent string) error {
+func (dp *DocumentProcessor) processSectionsInteractive(sections []TemplateSection, depth int) error {
 	for _, section := range sections {
-		if err := dp.processSectionInteractive(section, indent); err != nil {
+		if err := dp.processSectionInteractive(section, depth); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (dp *DocumentProcessor) processSectionYolo(section TemplateSection, indent string) error {
+func (dp *DocumentProcessor) processSectionYolo(section TemplateSection, depth int) error {
+	if err := dp.cancelled(); err != nil {
+		return err
+	}
+	if section.Repeatable {
+		return dp.processRepeatable(section, depth, false)
+	}
+	if include, err := dp.includeSection(section); err != nil || !include {
+		return err
+	}
+	section, err := dp.resolveSection(section)
+	if err != nil {
+		return err
+	}
+
 	// Add section header
-	dp.addToOutput(indent + "## " + section.Title)
+	dp.addToOutput(sectionHeading(section.Title, depth))
 	dp.addToOutput("")
 
-	// Process based on section type
-	switch section.Type {
-	case "paragraphs":
-		dp.addToOutput(indent + "Content to be determined through interactive process.")
-		dp.addToOutput("")
-	case "bullet-list":
-		dp.addToOutput(indent + "- Item 1")
-		dp.addToOutput(indent + "- Item 2")
-		dp.addToOutput("")
-	case "numbered-list":
-		dp.addToOutput(indent + "1. Item 1")
-		dp.addToOutput(indent + "2. Item 2")
-		dp.addToOutput("")
-	case "table":
-		dp.generateTable(section, indent)
-	default:
-		dp.addToOutput(indent + "Content to be determined through interactive process.")
-		dp.addToOutput("")
+	choice, err := dp.processChoices(section, false)
+	if err != nil {
+		return err
+	}
+
+	if dp.examples {
+		dp.addExampleGuidance(section)
+	}
+
+	// Draft with the content provider when there is one; otherwise template
+	// bodies are content scaffolds and other sections render by type
+	if dp.content != nil && isDraftable(section) {
+		if err := dp.draftSection(section); err != nil {
+			return err
+		}
+	} else if hasItemTemplate(section) {
+		if err := dp.renderItems(section, false); err != nil {
+			return err
+		}
+	} else if hasTemplateBody(section) {
+		if err := dp.renderTemplateBody(section, false); err != nil {
+			return err
+		}
+	} else {
+		switch section.Type {
+		case "choice":
+			dp.addToOutput(choice)
+			dp.addToOutput("")
+		case "mermaid":
+			if err := dp.generateMermaid(section, false); err != nil {
+				return err
+			}
+		case "paragraphs":
+			dp.addToOutput("Content to be determined through interactive process.")
+			dp.addToOutput("")
+		case "bullet-list":
+			dp.addToOutput("- Item 1")
+			dp.addToOutput("- Item 2")
+			dp.addToOutput("")
+		case "numbered-list":
+			for i, item := range []string{"Item 1", "Item 2"} {
+				dp.addToOutput(numberedListItem(section.Prefix, i+1, item))
+			}
+			dp.addToOutput("")
+		case "table":
+			if err := dp.generateTable(section, false); err != nil {
+				return err
+			}
+		default:
+			dp.addToOutput("Content to be determined through interactive process.")
+			dp.addToOutput("")
+		}
 	}
 
 	// Process nested sections
 	if len(section.Sections) > 0 {
-		return dp.processSectionsYolo(section.Sections, indent+"  ")
+		return dp.processSectionsYolo(section.Sections, depth+1)
 	}
 
 	return nil
 }
 
-func (dp *DocumentProcessor) processSectionInteractive(section TemplateSection, indent string) error {
+func (dp *DocumentProcessor) processSectionInteractive(section TemplateSection, depth int) error {
+	if err := dp.cancelled(); err != nil {
+		return err
+	}
+	if section.Repeatable {
+		return dp.processRepeatable(section, depth, true)
+	}
+	if include, err := dp.includeSection(section); err != nil || !include {
+		return err
+	}
+	section, err := dp.resolveSection(section)
+	if err != nil {
+		return err
+	}
+
 	// Add section header
-	dp.addToOutput(indent + "## " + section.Title)
+	dp.addToOutput(sectionHeading(section.Title, depth))
 	dp.addToOutput("")
 
+	choice, err := dp.processChoices(section, true)
+	if err != nil {
+		return err
+	}
+
 	// Show instruction
 	if section.Instruction != "" {
 		fmt.Printf("   📝 %s\n", section.Instruction)
 	}
+	dp.showExamples(section)
 
-	// Handle elicitation if required
-	if section.Elicit {
-		fmt.Printf("   🔄 ELICITATION REQUIRED\n")
-		if err := dp.handleElicitation(section); err != nil {
+	start := len(dp.output)
+	if hasItemTemplate(section) {
+		if err := dp.renderItems(section, true); err != nil {
+			return err
+		}
+	} else if hasTemplateBody(section) {
+		if err := dp.renderTemplateBody(section, true); err != nil {
 			return err
 		}
 	} else {
 		// Process based on section type
 		switch section.Type {
+		case "choice":
+			dp.addToOutput(choice)
+			dp.addToOutput("")
+		case "mermaid":
+			if err := dp.generateMermaid(section, true); err != nil {
+				return err
+			}
 		case "paragraphs":
 			content, err := dp.getUserInput("Enter paragraph content:")
 			if err != nil {
 				return err
 			}
-			dp.addToOutput(indent + content)
+			dp.addToOutput(content)
 			dp.addToOutput("")
 		case "bullet-list":
 			items, err := dp.getListInput("Enter bullet list items (empty line to finish):")
@@ -416,7 +1015,7 @@ func (dp *DocumentProcessor) processSectionInteractive(section TemplateSection,
 				return err
 			}
 			for _, item := range items {
-				dp.addToOutput(indent + "- " + item)
+				dp.addToOutput("- " + item)
 			}
 			dp.addToOutput("")
 		case "numbered-list":
@@ -425,88 +1024,149 @@ func (dp *DocumentProcessor) processSectionInteractive(section TemplateSection,
 				return err
 			}
 			for i, item := range items {
-				dp.addToOutput(fmt.Sprintf("%s%d. %s", indent, i+1, item))
+				dp.addToOutput(numberedListItem(section.Prefix, i+1, item))
 			}
 			dp.addToOutput("")
 		case "table":
-			dp.generateTable(section, indent)
+			if err := dp.generateTable(section, true); err != nil {
+				return err
+			}
 		default:
 			content, err := dp.getUserInput("Enter content:")
 			if err != nil {
 				return err
 			}
-			dp.addToOutput(indent + content)
+			dp.addToOutput(content)
 			dp.addToOutput("")
 		}
 	}
 
+	// Offer elicitation on the section's content if required
+	if section.Elicit {
+		fmt.Printf("   🔄 ELICITATION REQUIRED\n")
+		if err := dp.handleElicitation(section, start); err != nil {
+			return err
+		}
+	}
+
 	// Process nested sections
 	if len(section.Sections) > 0 {
-		return dp.processSectionsInteractive(section.Sections, indent+"  ")
+		return dp.processSectionsInteractive(section.Sections, depth+1)
 	}
 
 	return nil
 }
 
-func (dp *DocumentProcessor) handleElicitation(section TemplateSection) error {
-	fmt.Printf("   \n   📋 MANDATORY 1-9 OPTIONS FORMAT:\n")
-	fmt.Printf("   1. Proceed to next section\n")
-	fmt.Printf("   2. Stakeholder Interview\n")
-	fmt.Printf("   3. Competitive Analysis\n")
-	fmt.Printf("   4. User Journey Mapping\n")
-	fmt.Printf("   5. Risk Assessment\n")
-	fmt.Printf("   6. Technical Deep Dive\n")
-	fmt.Printf("   7. Data Analysis\n")
-	fmt.Printf("   8. Scenario Planning\n")
-	fmt.Printf("   9. Expert Consultation\n")
-	fmt.Printf("   \n   Select 1-9 or type your question/feedback: ")
-
-	input, err := dp.getUserInput("")
-	if err != nil {
-		return err
+// resolveSection interpolates a section's title and instruction with the
+// variables known at render time, such as earlier choices
+func (dp *DocumentProcessor) resolveSection(section TemplateSection) (TemplateSection, error) {
+	if err := dp.variableScope().interpolateFields(&section.Title, &section.Instruction); err != nil {
+		return section, err
 	}
+	section.Title = dp.applyChoices(section.Title)
+	return section, nil
+}
 
-	if choice, err := strconv.Atoi(input); err == nil && choice >= 1 && choice <= 9 {
-		if choice == 1 {
-			fmt.Printf("   ✅ Proceeding to next section\n")
-			dp.addToOutput("Content to be determined through elicitation process.")
-			dp.addToOutput("")
-		} else {
-			fmt.Printf("   🔍 Executing elicitation method %d\n", choice)
-			fmt.Printf("   📊 Method completed - insights gathered\n")
-			dp.addToOutput(fmt.Sprintf("Content determined through elicitation method %d.", choice))
-			dp.addToOutput("")
-		}
-	} else {
-		fmt.Printf("   💬 User feedback recorded: %s\n", input)
-		fmt.Printf("   ✅ Feedback processed\n")
-		dp.addToOutput("Content determined through user feedback: " + input)
-		dp.addToOutput("")
+// sectionHeading returns the markdown heading for a section; top-level sections
+// are level 2 (the document title is level 1) and each nesting level adds one,
+// up to markdown's maximum of 6
+func sectionHeading(title string, depth int) string {
+	level := depth + 2
+	if level > 6 {
+		level = 6
 	}
+	return strings.Repeat("#", level) + " " + title
+}
 
-	return nil
+// numberedListItem formats a numbered list item; with a prefix such as FR the
+// item is identified as FR1, FR2... instead of a plain ordinal
+func numberedListItem(prefix string, number int, item string) string {
+	if prefix == "" {
+		return fmt.Sprintf("%d. %s", number, item)
+	}
+
+	id := fmt.Sprintf("%s%d", prefix, number)
+	if strings.HasPrefix(item, id+":") {
+		return "- " + item
+	}
+	return fmt.Sprintf("- %s: %s", id, item)
 }
 
 func (dp *DocumentProcessor) getUserInput(prompt string) (string, error) {
 	if prompt != "" {
 		fmt.Printf("   %s ", prompt)
 	}
-	input, err := dp.reader.ReadString('\n')
+	input, err := readLine(dp.ctx, dp.reader)
 	if err != nil {
-		return "", err
+		return "", wrapStepError(ErrorClassUserInput, err, "error reading input")
 	}
 	return strings.TrimSpace(input), nil
 }
 
+// readLine reads a line from reader, giving up once ctx is done. Terminal
+// reads cannot be interrupted, so a read still blocked on input is abandoned;
+// nothing reads from reader after ctx is done.
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	if ctx == nil {
+		return reader.ReadString('\n')
+	}
+	if err := ctx.Err(); err != nil {
+		return "", contextError(err, "input cancelled")
+	}
+
+	type line struct {
+		text string
+		err  error
+	}
+	lines := make(chan line, 1)
+	go func() {
+		text, err := reader.ReadString('\n')
+		lines <- line{text, err}
+	}()
+
+	select {
+	case read := <-lines:
+		return read.text, read.err
+	case <-ctx.Done():
+		return "", contextError(ctx.Err(), "input cancelled")
+	}
+}
+
+// cancelled returns the context's error once the run is cancelled or times out
+func (dp *DocumentProcessor) cancelled() error {
+	if dp.ctx != nil && dp.ctx.Err() != nil {
+		return contextError(dp.ctx.Err(), "template processing cancelled")
+	}
+	return nil
+}
+
+// askYesNo asks a y/n question until it gets a valid answer
+func (dp *DocumentProcessor) askYesNo(prompt string) (bool, error) {
+	for {
+		input, err := dp.getUserInput(prompt + " (y/n)?")
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(input) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Printf("   Please answer y or n\n")
+	}
+}
+
 func (dp *DocumentProcessor) getListInput(prompt string) ([]string, error) {
 	var items []string
 	fmt.Printf("   %s\n", prompt)
 
 	for {
 		fmt.Printf("   > ")
-		input, err := dp.reader.ReadString('\n')
+		input, err := readLine(dp.ctx, dp.reader)
 		if err != nil {
-			return nil, err
+			return nil, wrapStepError(ErrorClassUserInput, err, "error reading list input")
 		}
 		input = strings.TrimSpace(input)
 		if input == "" {
@@ -518,113 +1178,41 @@ func (dp *DocumentProcessor) getListInput(prompt string) ([]string, error) {
 	return items, nil
 }
 
-func (dp *DocumentProcessor) generateTable(section TemplateSection, indent string) {
-	if len(section.Columns) == 0 {
-		dp.addToOutput(indent + "| Column 1 | Column 2 |")
-		dp.addToOutput(indent + "|----------|----------|")
-		dp.addToOutput(indent + "| Data 1   | Data 2   |")
-	} else {
-		// Generate header row
-		header := indent + "|"
-		separator := indent + "|"
-		for _, col := range section.Columns {
-			header += " " + col + " |"
-			separator += "----------|"
-		}
-		dp.addToOutput(header)
-		dp.addToOutput(separator)
-		dp.addToOutput(indent + "| Sample Data | Sample Data |")
-	}
-	dp.addToOutput("")
-}
-
 func (dp *DocumentProcessor) addToOutput(line string) {
 	dp.output = append(dp.output, line)
 }
 
 func (dp *DocumentProcessor) saveToFile(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
 	content := strings.Join(dp.output, "\n")
 	return ioutil.WriteFile(filename, []byte(content), 0644)
 }
 
-func (dp *DocumentProcessor) substituteVariables(text string) string {
-	// Simple variable substitution - can be enhanced
-	for key, value := range dp.variables {
-		placeholder := "{{" + key + "}}"
-		text = strings.ReplaceAll(text, placeholder, fmt.Sprintf("%v", value))
-	}
-	return text
-}
-
 // ChecklistProcessor methods
 
 func (cp *ChecklistProcessor) loadChecklist(filename string) error {
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
-		return err
-	}
-
-	// Try to parse as YAML first
-	if err := yaml.Unmarshal(data, &cp.checklist); err != nil {
-		// If YAML fails, try to parse as markdown checklist
-		return cp.parseMarkdownChecklist(string(data))
+		return NewStepError(ErrorClassFilesystem, err, "error reading checklist file %s", filename)
 	}
 
-	return nil
-}
-
-func (cp *ChecklistProcessor) parseMarkdownChecklist(content string) error {
-	lines := strings.Split(content, "\n")
-	cp.checklist = Checklist{
-		Name:     "Parsed Markdown Checklist",
-		Version:  "1.0",
-		Sections: []ChecklistSection{},
-	}
-
-	var currentSection *ChecklistSection
-	var currentItem *ChecklistItem
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Check for section headers
-		if strings.HasPrefix(line, "## ") {
-			if currentSection != nil {
-				cp.checklist.Sections = append(cp.checklist.Sections, *currentSection)
-			}
-			currentSection = &ChecklistSection{
-				Title: strings.TrimPrefix(line, "## "),
-				Items: []ChecklistItem{},
-			}
-			continue
-		}
+	cp.results = make(map[string]ChecklistItem)
 
-		// Check for checklist items
-		if strings.HasPrefix(line, "- [") && currentSection != nil {
-			if currentItem != nil {
-				currentSection.Items = append(currentSection.Items, *currentItem)
-			}
-			text := strings.TrimPrefix(line, "- [ ] ")
-			currentItem = &ChecklistItem{
-				Text:   text,
-				Status: "pending",
-			}
-			continue
-		}
+	// Markdown checklists are parsed as such; otherwise try YAML first
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".md" || ext == ".markdown" {
+		err = cp.parseMarkdownChecklist(string(data))
+	} else if yamlErr := yaml.Unmarshal(data, &cp.checklist); yamlErr != nil {
+		// If YAML fails, try to parse as markdown checklist
+		err = cp.parseMarkdownChecklist(string(data))
 	}
 
-	// Add final section and item
-	if currentSection != nil {
-		if currentItem != nil {
-			currentSection.Items = append(currentSection.Items, *currentItem)
-		}
-		cp.checklist.Sections = append(cp.checklist.Sections, *currentSection)
+	if cp.checklist.ID == "" {
+		cp.checklist.ID = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
 	}
-
-	return nil
+	return err
 }
 
 func (cp *ChecklistProcessor) processYolo() error {
@@ -637,17 +1225,24 @@ func (cp *ChecklistProcessor) processYolo() error {
 
 	for _, section := range cp.checklist.Sections {
 		for _, item := range section.Items {
-			totalItems++
-			// Simulate validation - in real implementation, this would analyze actual content
-			status := cp.simulateValidation(item)
-			cp.results[item.ID] = ChecklistItem{
-				ID:     item.ID,
-				Text:   item.Text,
-				Status: status,
-				Notes:  "Batch validation completed",
+			result := item
+			if checkedInSource(item) {
+				result.Notes = "Checked in the checklist source"
+			} else {
+				result.Notes = "Not evaluated: no evaluator for this step"
+			}
+			cp.results[item.ID] = result
+		}
+
+		if cp.evaluator != nil {
+			if err := cp.evaluateSection(section); err != nil {
+				return err
 			}
+		}
 
-			switch status {
+		for _, item := range section.Items {
+			totalItems++
+			switch cp.results[item.ID].Status {
 			case "pass":
 				passedItems++
 			case "fail":
@@ -668,33 +1263,47 @@ func (cp *ChecklistProcessor) processYolo() error {
 
 func (cp *ChecklistProcessor) processInteractive() error {
 	fmt.Printf("   👤 Interactive checklist validation\n")
+	showGuidance("Checklist guidance", cp.checklist.Guidance)
 
 	for i, section := range cp.checklist.Sections {
 		fmt.Printf("\n   📑 Section %d/%d: %s\n", i+1, len(cp.checklist.Sections), section.Title)
 		fmt.Printf("   📋 Items: %d\n", len(section.Items))
+		showGuidance("Section guidance", section.Guidance)
 
+		category := ""
 		for j, item := range section.Items {
-			fmt.Printf("\n   📝 Item %d/%d: %s\n", j+1, len(section.Items), item.Text)
+			if item.Category != category {
+				category = item.Category
+				showGuidance(category, section.CategoryGuidance[category])
+			}
+
+			fmt.Printf("\n   📝 Item %d/%d [%s]: %s\n", j+1, len(section.Items), item.ID, item.Text)
 			if item.Criteria != "" {
 				fmt.Printf("   🎯 Criteria: %s\n", item.Criteria)
 			}
+			showGuidance("Guidance", item.Guidance)
 
-			status, notes := cp.getUserValidation()
-			cp.results[item.ID] = ChecklistItem{
-				ID:     item.ID,
-				Text:   item.Text,
-				Status: status,
-				Notes:  notes,
+			if checkedInSource(item) {
+				fmt.Printf("   ☑️  Already checked: %s\n", item.Status)
+				cp.results[item.ID] = item
+				continue
 			}
 
-			fmt.Printf("   ✅ Recorded: %s\n", status)
+			result := item
+			var err error
+			if result.Status, result.Notes, err = cp.getUserValidation(); err != nil {
+				return err
+			}
+			cp.results[item.ID] = result
+
+			fmt.Printf("   ✅ Recorded: %s\n", result.Status)
 		}
 	}
 
 	return nil
 }
 
-func (cp *ChecklistProcessor) getUserValidation() (string, string) {
+func (cp *ChecklistProcessor) getUserValidation() (string, string, error) {
 	fmt.Printf("   Select validation status:\n")
 	fmt.Printf("   1. ✅ PASS - Meets requirements\n")
 	fmt.Printf("   2. ⚠️ PARTIAL - Partially meets requirements\n")
@@ -702,7 +1311,11 @@ func (cp *ChecklistProcessor) getUserValidation() (string, string) {
 	fmt.Printf("   4. ⏭️ N/A - Not applicable\n")
 	fmt.Printf("   Choice: ")
 
-	input, _ := cp.reader.ReadString('\n')
+	// Read errors leave the item pending; only a cancelled run stops validation
+	input, err := readLine(cp.ctx, cp.reader)
+	if err != nil && ErrorClassOf(err) != "" {
+		return "", "", err
+	}
 	input = strings.TrimSpace(input)
 
 	var status string
@@ -720,17 +1333,13 @@ func (cp *ChecklistProcessor) getUserValidation() (string, string) {
 	}
 
 	fmt.Printf("   📝 Notes (optional): ")
-	notes, _ := cp.reader.ReadString('\n')
+	notes, err := readLine(cp.ctx, cp.reader)
+	if err != nil && ErrorClassOf(err) != "" {
+		return "", "", err
+	}
 	notes = strings.TrimSpace(notes)
 
-	return status, notes
-}
-
-func (cp *ChecklistProcessor) simulateValidation(item ChecklistItem) string {
-	// Simple simulation - in real implementation, this would analyze actual content
-	// For now, randomly assign statuses to demonstrate the framework
-	statuses := []string{"pass", "partial", "fail"}
-	return statuses[len(item.Text)%len(statuses)]
+	return status, notes, nil
 }
 
 func (cp *ChecklistProcessor) generateReport(filename string) error {
@@ -785,7 +1394,7 @@ func (cp *ChecklistProcessor) generateReport(filename string) error {
 		for _, item := range section.Items {
 			if result, exists := cp.results[item.ID]; exists {
 				status := cp.getStatusEmoji(result.Status)
-				report = append(report, fmt.Sprintf("- %s %s", status, item.Text))
+				report = append(report, fmt.Sprintf("- %s %s %s", status, item.ID, item.Text))
 				if result.Notes != "" {
 					report = append(report, fmt.Sprintf("  *Notes:* %s", result.Notes))
 				}
@@ -802,10 +1411,8 @@ func (cp *ChecklistProcessor) generateReport(filename string) error {
 		report = append(report, "### Critical Issues (Must Fix)")
 		report = append(report, "The following items require immediate attention:")
 		report = append(report, "")
-		for _, item := range cp.results {
-			if item.Status == "fail" {
-				report = append(report, fmt.Sprintf("- %s", item.Text))
-			}
+		for _, item := range cp.resultsWithStatus("fail") {
+			report = append(report, fmt.Sprintf("- %s %s (%s)", item.ID, item.Text, item.Severity))
 		}
 		report = append(report, "")
 	}
@@ -814,14 +1421,15 @@ func (cp *ChecklistProcessor) generateReport(filename string) error {
 		report = append(report, "### Improvement Opportunities")
 		report = append(report, "Consider addressing these partial items:")
 		report = append(report, "")
-		for _, item := range cp.results {
-			if item.Status == "partial" {
-				report = append(report, fmt.Sprintf("- %s", item.Text))
-			}
+		for _, item := range cp.resultsWithStatus("partial") {
+			report = append(report, fmt.Sprintf("- %s %s (%s)", item.ID, item.Text, item.Severity))
 		}
 		report = append(report, "")
 	}
 
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
 	return ioutil.WriteFile(filename, []byte(strings.Join(report, "\n")), 0644)
 }
 
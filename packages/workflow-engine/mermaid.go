@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mermaidDeclarationPattern matches a first line that declares a diagram type,
+// e.g. `graph LR` or a misspelt `sequenceDiagramm`
+var mermaidDeclarationPattern = regexp.MustCompile(`^[A-Za-z][\w-]*(\s+(TD|TB|LR|RL|BT))?$`)
+
+// mermaidType describes a section's mermaid_type: the declaration prepended to
+// diagrams without one and the keyword prefixes a diagram of that type may use
+type mermaidType struct {
+	preamble string
+	keywords []string
+}
+
+var mermaidTypes = map[string]mermaidType{
+	"graph":     {"graph TD", []string{"graph", "flowchart"}},
+	"flowchart": {"flowchart TD", []string{"graph", "flowchart"}},
+	"sequence":  {"sequenceDiagram", []string{"sequenceDiagram"}},
+	"class":     {"classDiagram", []string{"classDiagram"}},
+	"state":     {"stateDiagram-v2", []string{"stateDiagram"}},
+	"er":        {"erDiagram", []string{"erDiagram"}},
+	"gantt":     {"gantt", []string{"gantt"}},
+	"pie":       {"pie", []string{"pie"}},
+	"journey":   {"journey", []string{"journey"}},
+	"c4":        {"C4Context", []string{"C4"}},
+	"mindmap":   {"mindmap", []string{"mindmap"}},
+}
+
+// mermaidKeywords are the diagram declarations a diagram may start with
+var mermaidKeywords = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "stateDiagram-v2", "stateDiagram",
+	"erDiagram", "gantt", "pie", "journey", "C4Context", "C4Container", "C4Component",
+	"C4Dynamic", "C4Deployment", "mindmap", "timeline", "gitGraph", "quadrantChart",
+}
+
+// mermaidBlockOpeners start blocks that must be closed with `end`
+var mermaidBlockOpeners = map[string]bool{
+	"subgraph": true, "loop": true, "alt": true, "opt": true, "par": true,
+	"critical": true, "break": true, "rect": true, "box": true,
+}
+
+// mermaidFileExtensions mark a diagram source that names a file to load
+var mermaidFileExtensions = map[string]bool{".mmd": true, ".mermaid": true}
+
+// generateMermaid renders a mermaid section as a fenced diagram. The diagram
+// comes from a variable named after the section id (diagram text, a .mmd file
+// or a step output reference such as {{steps.design.outputs.stdout}}), else the
+// section's `template:` once its placeholders resolve, else interactive input.
+// Diagrams that fail the sanity check are re-asked in interactive mode and
+// fail the step otherwise, so a broken diagram is never saved.
+func (dp *DocumentProcessor) generateMermaid(section TemplateSection, interactive bool) error {
+	diagram, err := dp.mermaidSource(section)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if diagram == "" && interactive {
+			if diagram, err = dp.getMermaidInput(section); err != nil {
+				return err
+			}
+		}
+		if diagram == "" {
+			diagram = fmt.Sprintf("%%%% TODO: %s", section.Title)
+		}
+
+		diagram = withMermaidPreamble(diagram, section.MermaidType)
+		problems := checkMermaid(diagram, section.MermaidType)
+		if len(problems) == 0 {
+			break
+		}
+
+		if !interactive {
+			return NewStepError(ErrorClassTemplate, nil, "mermaid diagram for %q: %s", section.ID, strings.Join(problems, "; ")).
+				WithRemediation("Fix the diagram source so blocks and brackets balance and it starts with a diagram keyword")
+		}
+		for _, problem := range problems {
+			fmt.Printf("   ⚠️  Diagram problem: %s\n", problem)
+		}
+		diagram = ""
+	}
+
+	dp.addToOutput("```mermaid")
+	for _, line := range strings.Split(diagram, "\n") {
+		dp.addToOutput(line)
+	}
+	dp.addToOutput("```")
+	dp.addToOutput("")
+	return nil
+}
+
+// mermaidSource returns the diagram supplied for a section, if any
+func (dp *DocumentProcessor) mermaidSource(section TemplateSection) (string, error) {
+	scope := dp.variableScope()
+
+	source := ""
+	if value, ok := scope.Lookup(section.ID); ok {
+		interpolated, err := scope.Interpolate(fmt.Sprintf("%v", value))
+		if err != nil {
+			return "", err
+		}
+		source = interpolated
+	} else if section.Template != "" {
+		interpolated, err := scope.Interpolate(section.Template)
+		if err != nil {
+			return "", err
+		}
+		if !variablePattern.MatchString(interpolated) {
+			source = interpolated
+		}
+	}
+
+	source = strings.TrimSpace(source)
+	if mermaidFileExtensions[strings.ToLower(filepath.Ext(source))] && !strings.Contains(source, "\n") {
+		return dp.loadMermaidFile(source)
+	}
+	return source, nil
+}
+
+// loadMermaidFile reads a diagram file, resolved like template paths when possible
+func (dp *DocumentProcessor) loadMermaidFile(path string) (string, error) {
+	if dp.paths != nil {
+		resolved, err := dp.paths.Resolve(path)
+		if err != nil {
+			return "", wrapStepError(ErrorClassFilesystem, err, "error locating mermaid diagram")
+		}
+		path = resolved
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", NewStepError(ErrorClassFilesystem, err, "error reading mermaid diagram %s", path)
+	}
+	fmt.Printf("   📂 Diagram: %s\n", path)
+	return strings.TrimSpace(string(data)), nil
+}
+
+// getMermaidInput reads a diagram line by line, keeping indentation; a single
+// line naming a .mmd file loads that file instead
+func (dp *DocumentProcessor) getMermaidInput(section TemplateSection) (string, error) {
+	fmt.Printf("   📈 Enter the %s diagram, or a .mmd file path (empty line to finish):\n", section.MermaidType)
+
+	var lines []string
+	for {
+		fmt.Printf("   > ")
+		line, err := readLine(dp.ctx, dp.reader)
+		if err != nil {
+			return "", wrapStepError(ErrorClassUserInput, err, "error reading diagram input")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 1 && mermaidFileExtensions[strings.ToLower(filepath.Ext(strings.TrimSpace(lines[0])))] {
+		return dp.loadMermaidFile(strings.TrimSpace(lines[0]))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// mermaidKeyword returns the diagram keyword the diagram starts with, if any
+func mermaidKeyword(diagram string) string {
+	for _, line := range strings.Split(diagram, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%%") {
+			continue
+		}
+		first := strings.Fields(line)[0]
+		for _, keyword := range mermaidKeywords {
+			if first == keyword {
+				return keyword
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// withMermaidPreamble prepends the declared diagram type when the diagram has
+// no declaration of its own
+func withMermaidPreamble(diagram, diagramType string) string {
+	for _, line := range strings.Split(diagram, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "%%") {
+			continue
+		}
+		if mermaidDeclarationPattern.MatchString(line) {
+			return diagram
+		}
+		break
+	}
+
+	declared, ok := mermaidTypes[diagramType]
+	if !ok {
+		declared = mermaidTypes["graph"]
+	}
+	return declared.preamble + "\n" + diagram
+}
+
+// checkMermaid performs a lightweight sanity check: a known diagram keyword
+// matching the declared type, blocks closed with `end` and balanced brackets
+func checkMermaid(diagram, diagramType string) []string {
+	var problems []string
+
+	keyword := mermaidKeyword(diagram)
+	if keyword == "" {
+		problems = append(problems, "diagram does not start with a known diagram keyword")
+	} else if declared, ok := mermaidTypes[diagramType]; ok {
+		matches := false
+		for _, prefix := range declared.keywords {
+			if strings.HasPrefix(keyword, prefix) {
+				matches = true
+			}
+		}
+		if !matches {
+			problems = append(problems, fmt.Sprintf("diagram is %s but the section declares mermaid_type %s", keyword, diagramType))
+		}
+	}
+
+	open := 0
+	brackets := map[rune]int{}
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	for number, line := range strings.Split(diagram, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+
+		first := strings.Fields(trimmed)[0]
+		switch {
+		case mermaidBlockOpeners[first]:
+			open++
+		case first == "end":
+			if open == 0 {
+				problems = append(problems, fmt.Sprintf("line %d: `end` without an open block", number+1))
+			} else {
+				open--
+			}
+		}
+
+		inQuote := false
+		for _, r := range trimmed {
+			switch {
+			case r == '"':
+				inQuote = !inQuote
+			case inQuote:
+			case r == '(' || r == '[' || r == '{':
+				brackets[r]++
+			case pairs[r] != 0:
+				brackets[pairs[r]]--
+			}
+		}
+	}
+
+	if open > 0 {
+		problems = append(problems, fmt.Sprintf("%d block(s) not closed with `end`", open))
+	}
+	for _, r := range []rune{'(', '[', '{'} {
+		if brackets[r] != 0 {
+			problems = append(problems, fmt.Sprintf("unbalanced %q brackets", string(r)))
+		}
+	}
+	return problems
+}
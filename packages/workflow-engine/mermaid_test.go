@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func renderMermaid(t *testing.T, section TemplateSection, variables map[string]interface{}, interactive bool, input string) (string, error) {
+	t.Helper()
+
+	dp := newTestProcessor(false, variables, input)
+	err := dp.generateMermaid(section, interactive)
+	return strings.Join(dp.output, "\n"), err
+}
+
+func TestGenerateMermaid_AddsPreambleAndFence(t *testing.T) {
+	section := TemplateSection{ID: "auth-flow", Type: "mermaid", MermaidType: "sequence", Template: "{{auth_flow_diagram}}"}
+	diagram := "User->>API: login\nalt valid\n  API-->>User: token\nelse invalid\n  API-->>User: 401\nend"
+
+	got, err := renderMermaid(t, section, map[string]interface{}{"auth_flow_diagram": diagram}, false, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "```mermaid\nsequenceDiagram\n" + diagram + "\n```\n"
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestGenerateMermaid_LoadsFileFromVariable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sitemap.mmd")
+	if err := ioutil.WriteFile(path, []byte("graph LR\n  A[Home] --> B[Dashboard]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	section := TemplateSection{ID: "sitemap", Type: "mermaid", MermaidType: "graph"}
+	got, err := renderMermaid(t, section, map[string]interface{}{"sitemap": path}, false, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "graph LR\n  A[Home] --> B[Dashboard]") || strings.Contains(got, "graph TD") {
+		t.Errorf("Expected the file's diagram without an extra preamble:\n%s", got)
+	}
+}
+
+func TestGenerateMermaid_FlagsBrokenDiagrams(t *testing.T) {
+	section := TemplateSection{ID: "flow", Type: "mermaid", MermaidType: "graph"}
+
+	_, err := renderMermaid(t, section, map[string]interface{}{"flow": "subgraph API\n  A[Gateway --> B"}, false, "")
+	if ErrorClassOf(err) != ErrorClassTemplate || !strings.Contains(err.Error(), "not closed") || !strings.Contains(err.Error(), "unbalanced") {
+		t.Errorf("Expected a template error naming the unclosed block and brackets, got %v", err)
+	}
+
+	// Interactive runs re-ask until the diagram is valid
+	got, err := renderMermaid(t, section, nil, true, "sequenceDiagram\n  A->>B: hi\n\nA --> B\n\n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(got, "graph TD\nA --> B") {
+		t.Errorf("Expected the corrected diagram:\n%s", got)
+	}
+}
+
+func TestCheckMermaid(t *testing.T) {
+	tests := []struct {
+		diagram  string
+		kind     string
+		problems int
+	}{
+		{"graph TD\n  A[\"label (x]\"] --> B", "graph", 0},
+		{"flowchart LR\n  A --> B", "graph", 0},
+		{"grahp TD\n  A --> B", "graph", 1},
+		{"sequenceDiagram\n  loop every minute\n    A->>B: ping\n  end\nend", "sequence", 1},
+		{"classDiagram\n  class A", "sequence", 1},
+	}
+
+	for _, tt := range tests {
+		if problems := checkMermaid(tt.diagram, tt.kind); len(problems) != tt.problems {
+			t.Errorf("checkMermaid(%q) = %v, want %d problem(s)", tt.diagram, problems, tt.problems)
+		}
+	}
+}
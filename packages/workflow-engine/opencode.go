@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultOpenCodeBinary is used when neither the workflow nor OPENCODE_BIN names a binary
+const defaultOpenCodeBinary = "opencode"
+
+// OpenCodeConfig configures how regular workflow steps invoke the opencode CLI
+type OpenCodeConfig struct {
+	Binary string   `yaml:"binary,omitempty"`
+	Args   []string `yaml:"args,omitempty"`
+}
+
+// OpenCodeResult holds the captured outcome of a single opencode invocation
+type OpenCodeResult struct {
+	Command  []string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// OpenCodeRunner spawns the opencode CLI and streams its output into the step log
+type OpenCodeRunner struct {
+	binary string
+	args   []string
+	log    io.Writer
+	logMu  sync.Mutex
+}
+
+// NewOpenCodeRunner creates a runner from workflow configuration.
+// The OPENCODE_BIN environment variable takes precedence over the configured binary.
+func NewOpenCodeRunner(config OpenCodeConfig) *OpenCodeRunner {
+	binary := config.Binary
+	if env := os.Getenv("OPENCODE_BIN"); env != "" {
+		binary = env
+	}
+	if binary == "" {
+		binary = defaultOpenCodeBinary
+	}
+
+	return &OpenCodeRunner{
+		binary: binary,
+		args:   config.Args,
+		log:    os.Stdout,
+	}
+}
+
+// buildArgs returns the argument list passed to the opencode binary for a step
+func (r *OpenCodeRunner) buildArgs(step WorkflowStep) []string {
+	message := fmt.Sprintf("@%s %s", step.Agent, step.Task)
+	if step.Prompt != "" {
+		message += ": " + step.Prompt
+	}
+
+	args := []string{"run"}
+	args = append(args, r.args...)
+	return append(args, message)
+}
+
+// Run executes opencode for a step, honoring ctx for cancellation
+func (r *OpenCodeRunner) Run(ctx context.Context, step WorkflowStep, stepNum int) (*OpenCodeResult, error) {
+	return r.run(ctx, step, stepNum, nil)
+}
+
+// RunPrompt executes opencode for a step with prompt sent on stdin, which
+// `opencode run` appends to its message. Generated prompts embed whole
+// documents and can exceed the 128 KiB Linux allows for one argument.
+func (r *OpenCodeRunner) RunPrompt(ctx context.Context, step WorkflowStep, stepNum int, prompt string) (*OpenCodeResult, error) {
+	step.Prompt = ""
+	return r.run(ctx, step, stepNum, strings.NewReader(prompt))
+}
+
+func (r *OpenCodeRunner) run(ctx context.Context, step WorkflowStep, stepNum int, stdin io.Reader) (*OpenCodeResult, error) {
+	args := r.buildArgs(step)
+	result := &OpenCodeResult{
+		Command:  append([]string{r.binary}, args...),
+		ExitCode: -1,
+	}
+
+	stdout := r.newStepLogWriter(fmt.Sprintf("   │ [step %d] ", stepNum))
+	stderr := r.newStepLogWriter(fmt.Sprintf("   │ [step %d] ⚠️ ", stepNum))
+
+	cmd := exec.CommandContext(ctx, r.binary, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Don't let grandchildren holding the pipes open block Wait after cancellation
+	cmd.WaitDelay = 2 * time.Second
+
+	if err := cmd.Start(); err != nil {
+		return result, NewStepError(ErrorClassAgent, err, "error starting %s", r.binary)
+	}
+
+	waitErr := cmd.Wait()
+	stdout.flush()
+	stderr.flush()
+
+	result.Stdout = stdout.capture.String()
+	result.Stderr = stderr.capture.String()
+	result.ExitCode = cmd.ProcessState.ExitCode()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return result, contextError(ctxErr, "opencode execution cancelled")
+	}
+
+	if waitErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(waitErr, &exitErr) {
+			return result, NewStepError(ErrorClassAgent, nil, "opencode exited with code %d", result.ExitCode)
+		}
+		return result, NewStepError(ErrorClassAgent, waitErr, "error running %s", r.binary)
+	}
+
+	return result, nil
+}
+
+// stepLogWriter captures process output and echoes it line by line into the step log
+type stepLogWriter struct {
+	runner  *OpenCodeRunner
+	prefix  string
+	capture strings.Builder
+	partial []byte
+}
+
+func (r *OpenCodeRunner) newStepLogWriter(prefix string) *stepLogWriter {
+	return &stepLogWriter{runner: r, prefix: prefix}
+}
+
+// Write implements io.Writer, emitting every complete line to the step log
+func (w *stepLogWriter) Write(p []byte) (int, error) {
+	w.capture.Write(p)
+	w.partial = append(w.partial, p...)
+
+	for {
+		idx := bytes.IndexByte(w.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		w.emit(string(w.partial[:idx]))
+		w.partial = w.partial[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush emits a trailing line that was not newline-terminated
+func (w *stepLogWriter) flush() {
+	if len(w.partial) > 0 {
+		w.emit(string(w.partial))
+		w.partial = nil
+	}
+}
+
+func (w *stepLogWriter) emit(line string) {
+	w.runner.logMu.Lock()
+	defer w.runner.logMu.Unlock()
+	fmt.Fprintf(w.runner.log, "%s%s\n", w.prefix, line)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeOpenCodeRunner returns a runner wired to testdata/fake-opencode.sh
+func newFakeOpenCodeRunner(t *testing.T) (*OpenCodeRunner, *bytes.Buffer) {
+	t.Helper()
+
+	binary, err := filepath.Abs(filepath.Join("testdata", "fake-opencode.sh"))
+	if err != nil {
+		t.Fatalf("Failed to resolve fake opencode binary: %v", err)
+	}
+
+	t.Setenv("OPENCODE_BIN", "")
+	runner := NewOpenCodeRunner(OpenCodeConfig{Binary: binary})
+	log := &bytes.Buffer{}
+	runner.log = log
+
+	return runner, log
+}
+
+func TestNewOpenCodeRunner_BinaryResolution(t *testing.T) {
+	t.Setenv("OPENCODE_BIN", "")
+	if runner := NewOpenCodeRunner(OpenCodeConfig{}); runner.binary != defaultOpenCodeBinary {
+		t.Errorf("Expected default binary %q, got %q", defaultOpenCodeBinary, runner.binary)
+	}
+
+	if runner := NewOpenCodeRunner(OpenCodeConfig{Binary: "/opt/opencode"}); runner.binary != "/opt/opencode" {
+		t.Errorf("Expected configured binary, got %q", runner.binary)
+	}
+
+	t.Setenv("OPENCODE_BIN", "/usr/local/bin/opencode-dev")
+	if runner := NewOpenCodeRunner(OpenCodeConfig{Binary: "/opt/opencode"}); runner.binary != "/usr/local/bin/opencode-dev" {
+		t.Errorf("Expected OPENCODE_BIN to take precedence, got %q", runner.binary)
+	}
+}
+
+func TestOpenCodeRunner_BuildArgs(t *testing.T) {
+	runner := &OpenCodeRunner{binary: "opencode", args: []string{"--model", "test"}}
+	step := WorkflowStep{Agent: "architect", Task: "design", Prompt: "Create design"}
+
+	args := runner.buildArgs(step)
+	expected := []string{"run", "--model", "test", "@architect design: Create design"}
+
+	if strings.Join(args, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected args %q, got %q", expected, args)
+	}
+}
+
+func TestOpenCodeRunner_CapturesOutput(t *testing.T) {
+	runner, log := newFakeOpenCodeRunner(t)
+	step := WorkflowStep{Agent: "dev", Task: "implement", Prompt: "Build it"}
+
+	result, err := runner.Run(context.Background(), step, 3)
+	if err != nil {
+		t.Fatalf("Expected successful run, got %v", err)
+	}
+
+	if result.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d", result.ExitCode)
+	}
+
+	if !strings.Contains(result.Stdout, "@dev implement: Build it") {
+		t.Errorf("Stdout not captured, got %q", result.Stdout)
+	}
+
+	if !strings.Contains(result.Stderr, "fake-opencode diagnostics") {
+		t.Errorf("Stderr not captured, got %q", result.Stderr)
+	}
+
+	if !strings.Contains(log.String(), "[step 3]") {
+		t.Errorf("Expected output streamed into step log, got %q", log.String())
+	}
+}
+
+func TestOpenCodeRunner_RunPromptUsesStdin(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+	step := WorkflowStep{Agent: "pm", Task: "create-doc", Prompt: "Create the PRD"}
+
+	// Larger than the 128 KiB Linux allows for a single argument
+	prompt := strings.Repeat("Document line\n", 20000)
+	result, err := runner.RunPrompt(context.Background(), step, 1, prompt)
+	if err != nil {
+		t.Fatalf("Expected successful run, got %v", err)
+	}
+
+	if got := result.Command[len(result.Command)-1]; got != "@pm create-doc" {
+		t.Errorf("Expected the prompt to stay out of the arguments, got %q", got)
+	}
+	if !strings.HasSuffix(result.Stdout, prompt) {
+		t.Errorf("Expected the prompt on stdin, got %d bytes of output", len(result.Stdout))
+	}
+}
+
+func TestOpenCodeRunner_NonZeroExit(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+	t.Setenv("FAKE_OPENCODE_EXIT", "3")
+
+	result, err := runner.Run(context.Background(), WorkflowStep{Agent: "dev"}, 1)
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit code")
+	}
+
+	if result.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", result.ExitCode)
+	}
+}
+
+func TestOpenCodeRunner_Cancellation(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+	t.Setenv("FAKE_OPENCODE_SLEEP", "5")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := runner.Run(ctx, WorkflowStep{Agent: "dev"}, 1)
+	if err == nil {
+		t.Fatal("Expected cancellation error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Cancellation did not stop the subprocess promptly (%v)", elapsed)
+	}
+}
+
+func TestExecuteRegularStep_RecordsOutput(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	engine := &WorkflowEngine{parallelExecutor: executor, opencode: runner}
+	steps := []WorkflowStep{{Agent: "pm", Task: "plan", Prompt: "Write plan"}}
+
+	if err := executor.ExecuteParallel(engine, steps); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	result := executor.GetResults()[0]
+	output, ok := result.Output.(*OpenCodeResult)
+	if !ok {
+		t.Fatalf("Expected *OpenCodeResult output, got %T", result.Output)
+	}
+
+	if !strings.Contains(output.Stdout, "@pm plan: Write plan") {
+		t.Errorf("Unexpected captured stdout: %q", output.Stdout)
+	}
+}
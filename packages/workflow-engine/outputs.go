@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Output sources a step can expose under `outputs`, by kind of step
+var stepOutputSources = map[string][]string{
+	"template":  {"file", "content", "transcript"},
+	"checklist": {"report", "pass_rate", "passed", "blocker_failures"},
+	"agent":     {"stdout", "stderr", "exit_code", "json:<path>"},
+}
+
+const stepOutputPrefix = "steps."
+
+// StepOutputs is the run-scoped store of named step outputs, referenced from
+// later steps as {{steps.<id>.outputs.<name>}}
+type StepOutputs struct {
+	values map[string]map[string]interface{}
+	mutex  sync.RWMutex
+}
+
+// NewStepOutputs creates an empty output store
+func NewStepOutputs() *StepOutputs {
+	return &StepOutputs{values: make(map[string]map[string]interface{})}
+}
+
+// Set stores a named output of a step
+func (o *StepOutputs) Set(stepID, name string, value interface{}) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.values[stepID] == nil {
+		o.values[stepID] = make(map[string]interface{})
+	}
+	o.values[stepID][name] = value
+}
+
+// Get returns a named output of a step
+func (o *StepOutputs) Get(stepID, name string) (interface{}, bool) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	value, ok := o.values[stepID][name]
+	return value, ok
+}
+
+// Lookup resolves a steps.<id>.outputs.<name> variable name
+func (o *StepOutputs) Lookup(name string) (interface{}, bool) {
+	stepID, output, ok := parseOutputReference(name)
+	if !ok {
+		return nil, false
+	}
+	return o.Get(stepID, output)
+}
+
+// parseOutputReference splits steps.<id>.outputs.<name> into its step id and output name
+func parseOutputReference(name string) (string, string, bool) {
+	if !strings.HasPrefix(name, stepOutputPrefix) {
+		return "", "", false
+	}
+
+	rest := strings.TrimPrefix(name, stepOutputPrefix)
+	sep := strings.LastIndex(rest, ".outputs.")
+	if sep <= 0 || sep+len(".outputs.") == len(rest) {
+		return "", "", false
+	}
+
+	return rest[:sep], rest[sep+len(".outputs."):], true
+}
+
+// stepOutputReference is a {{steps.<id>.outputs.<name>}} placeholder found in a step
+type stepOutputReference struct {
+	StepID string
+	Output string
+}
+
+// stepOutputReferences returns the step output placeholders used by a step's
+// prompt, task, file paths and variables
+func stepOutputReferences(step WorkflowStep) []stepOutputReference {
+	var refs []stepOutputReference
+	seen := make(map[stepOutputReference]bool)
+
+	texts := []string{step.Prompt, step.Task, step.Template, step.Checklist}
+	names := make([]string, 0, len(step.Variables))
+	for name := range step.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if text, ok := step.Variables[name].(string); ok {
+			texts = append(texts, text)
+		}
+	}
+
+	for _, text := range texts {
+		for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+			stepID, output, ok := parseOutputReference(match[1])
+			if !ok {
+				continue
+			}
+
+			ref := stepOutputReference{StepID: stepID, Output: output}
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// stepKind returns the kind of step, which determines the outputs it can expose
+func stepKind(step WorkflowStep) string {
+	switch {
+	case step.Template != "":
+		return "template"
+	case step.Checklist != "":
+		return "checklist"
+	default:
+		return "agent"
+	}
+}
+
+// validateOutputSource checks that a declared output source is valid for the step kind
+func validateOutputSource(step WorkflowStep, source string) error {
+	kind := stepKind(step)
+	for _, valid := range stepOutputSources[kind] {
+		if source == valid || (valid == "json:<path>" && strings.HasPrefix(source, "json:") && len(source) > len("json:")) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s output source %q (expected one of %s)",
+		kind, source, strings.Join(stepOutputSources[kind], ", "))
+}
+
+// recordOutputs extracts a successful step's declared outputs into the run context
+func (pe *ParallelExecutor) recordOutputs(step WorkflowStep, stepIndex int, output interface{}) error {
+	names := make([]string, 0, len(step.Outputs))
+	for name := range step.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	id := stepID(step, stepIndex)
+	for _, name := range names {
+		value, err := extractStepOutput(step.Outputs[name], output)
+		if err != nil {
+			return NewStepError(ErrorClassAgent, err, "error extracting output %q of step %d (%s)", name, stepIndex+1, id)
+		}
+		pe.outputs.Set(id, name, value)
+	}
+
+	return nil
+}
+
+// restoreOutputs re-extracts the outputs of a step restored from a checkpoint
+func (pe *ParallelExecutor) restoreOutputs(step WorkflowStep, stepIndex int) {
+	pe.mutex.RLock()
+	result, exists := pe.stepResults[stepIndex]
+	pe.mutex.RUnlock()
+
+	if !exists || !result.Success {
+		return
+	}
+
+	if err := pe.recordOutputs(step, stepIndex, result.Output); err != nil {
+		fmt.Printf("   ⚠️  %v\n", err)
+	}
+}
+
+// extractStepOutput evaluates an output source against a step's result output
+func extractStepOutput(source string, output interface{}) (interface{}, error) {
+	switch out := output.(type) {
+	case *TemplateStepOutput:
+		switch source {
+		case "file":
+			return out.File, nil
+		case "content":
+			return strings.Join(out.Document, "\n"), nil
+		case "transcript":
+			return out.Transcript, nil
+		}
+	case *ChecklistStepOutput:
+		switch source {
+		case "report":
+			return out.Report, nil
+		case "pass_rate":
+			return checklistPassRate(out.Results), nil
+		case "passed":
+			return out.Passed, nil
+		case "blocker_failures":
+			return len(out.Blockers), nil
+		}
+	case *OpenCodeResult:
+		switch {
+		case source == "stdout":
+			return strings.TrimSpace(out.Stdout), nil
+		case source == "stderr":
+			return strings.TrimSpace(out.Stderr), nil
+		case source == "exit_code":
+			return out.ExitCode, nil
+		case strings.HasPrefix(source, "json:"):
+			return extractJSONField(out.Stdout, strings.TrimPrefix(source, "json:"))
+		}
+	case nil:
+		return nil, fmt.Errorf("step produced no output")
+	}
+
+	return nil, fmt.Errorf("output source %q is not available for %T", source, output)
+}
+
+// checklistPassRate returns the percentage of applicable checklist items that passed
+func checklistPassRate(results map[string]ChecklistItem) float64 {
+	applicable, passed := 0, 0
+	for _, item := range results {
+		if item.Status == "n/a" {
+			continue
+		}
+		applicable++
+		if item.Status == "pass" {
+			passed++
+		}
+	}
+
+	if applicable == 0 {
+		return 0
+	}
+	return math.Round(float64(passed)*1000/float64(applicable)) / 10
+}
+
+// extractJSONField parses agent stdout as JSON and returns the value at a dot
+// path. When stdout mixes logs and JSON, the last line holding valid JSON is used.
+func extractJSONField(stdout, path string) (interface{}, error) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stdout)), &document); err != nil {
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		parsed := false
+		for i := len(lines) - 1; i >= 0 && !parsed; i-- {
+			parsed = json.Unmarshal([]byte(strings.TrimSpace(lines[i])), &document) == nil
+		}
+		if !parsed {
+			return nil, fmt.Errorf("stdout is not valid JSON")
+		}
+	}
+
+	value := document
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("JSON field %q not found", path)
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("JSON index %q out of range in %q", key, path)
+			}
+			value = node[index]
+		default:
+			return nil, fmt.Errorf("JSON field %q not found", path)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOutputReference(t *testing.T) {
+	tests := []struct {
+		name   string
+		stepID string
+		output string
+		ok     bool
+	}{
+		{"steps.design.outputs.file", "design", "file", true},
+		{"steps.step-1.outputs.summary", "step-1", "summary", true},
+		{"steps.design.file", "", "", false},
+		{"steps..outputs.file", "", "", false},
+		{"steps.design.outputs.", "", "", false},
+		{"project_name", "", "", false},
+	}
+
+	for _, tt := range tests {
+		stepID, output, ok := parseOutputReference(tt.name)
+		if stepID != tt.stepID || output != tt.output || ok != tt.ok {
+			t.Errorf("parseOutputReference(%q) = (%q, %q, %t), want (%q, %q, %t)",
+				tt.name, stepID, output, ok, tt.stepID, tt.output, tt.ok)
+		}
+	}
+}
+
+func TestBuildDependencyGraph_OutputReferencesCreateEdges(t *testing.T) {
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	// No ids or depends_on: the references alone order the steps
+	steps := []WorkflowStep{
+		{Agent: "pm", Prompt: "Write the PRD", Outputs: map[string]string{"summary": "stdout"}},
+		{Agent: "architect", Prompt: "Design from {{steps.step-1.outputs.summary}}"},
+		{Agent: "qa", Prompt: "Review"},
+	}
+
+	graph, err := executor.BuildDependencyGraph(steps)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+
+	if deps := graph.Steps[1].Dependencies; len(deps) != 1 || deps[0] != 0 {
+		t.Errorf("Expected step 2 to depend on step 1, got %v", deps)
+	}
+	if graph.InDegree[2] != 0 {
+		t.Errorf("Expected step 3 to stay independent, got in-degree %d", graph.InDegree[2])
+	}
+
+	// A reference duplicating depends_on adds no second edge
+	steps = []WorkflowStep{
+		{ID: "prd", Outputs: map[string]string{"summary": "stdout"}},
+		{ID: "design", DependsOn: []string{"prd"}, Prompt: "{{steps.prd.outputs.summary}}"},
+	}
+	graph, err = executor.BuildDependencyGraph(steps)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if graph.InDegree[1] != 1 {
+		t.Errorf("Expected a single edge into design, got in-degree %d", graph.InDegree[1])
+	}
+}
+
+func TestStepOutputReferences_Variables(t *testing.T) {
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	steps := []WorkflowStep{
+		{ID: "prd", Template: "prd-tmpl.yaml", Outputs: map[string]string{"file": "file"}},
+		{ID: "validate", Checklist: "pm-checklist.md", Variables: map[string]interface{}{
+			"target_document": "{{steps.prd.outputs.file}}",
+			"retries":         2,
+		}},
+	}
+
+	graph, err := executor.BuildDependencyGraph(steps)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if deps := graph.Steps[1].Dependencies; len(deps) != 1 || deps[0] != 0 {
+		t.Errorf("Expected the variable reference to make step 2 depend on step 1, got %v", deps)
+	}
+
+	executor.outputs.Set("prd", "file", "docs/prd.md")
+	engine := &WorkflowEngine{parallelExecutor: executor, strictVariables: true}
+	step := steps[1]
+	step.Prompt = "Validate {{target_document}}"
+
+	resolved, err := engine.resolveStep(step)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resolved.Variables["target_document"] != "docs/prd.md" || resolved.Variables["retries"] != 2 {
+		t.Errorf("Expected step variables to be interpolated, got %v", resolved.Variables)
+	}
+	if resolved.Prompt != "Validate docs/prd.md" {
+		t.Errorf("Expected the prompt to see the resolved variable, got %q", resolved.Prompt)
+	}
+	if step.Variables["target_document"] != "{{steps.prd.outputs.file}}" {
+		t.Error("resolveStep should not modify the original step")
+	}
+
+	if target, _ := engine.variableScopeFor(resolved).Lookup("target_document"); target != "docs/prd.md" {
+		t.Errorf("Expected prepareEvaluation to see the resolved target_document, got %v", target)
+	}
+}
+
+func TestValidateStepReferences_Outputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []WorkflowStep
+		want  string
+	}{
+		{
+			name:  "unknown step",
+			steps: []WorkflowStep{{ID: "a", Prompt: "{{steps.missing.outputs.x}}"}},
+			want:  `unknown step id "missing"`,
+		},
+		{
+			name: "undeclared output",
+			steps: []WorkflowStep{
+				{ID: "a", Outputs: map[string]string{"summary": "stdout"}},
+				{ID: "b", Prompt: "{{steps.a.outputs.file}}"},
+			},
+			want: `does not declare output "file"`,
+		},
+		{
+			name:  "own output",
+			steps: []WorkflowStep{{ID: "a", Prompt: "{{steps.a.outputs.x}}", Outputs: map[string]string{"x": "stdout"}}},
+			want:  "references its own output",
+		},
+		{
+			name:  "source for wrong step kind",
+			steps: []WorkflowStep{{ID: "a", Checklist: "c.md", Outputs: map[string]string{"doc": "file"}}},
+			want:  `unknown checklist output source "file"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStepReferences(tt.steps)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestExtractStepOutput(t *testing.T) {
+	template := &TemplateStepOutput{File: "docs/prd.md", Document: []string{"# PRD", ""}, Transcript: "docs/prd.elicitation.md"}
+	if value, err := extractStepOutput("file", template); err != nil || value != "docs/prd.md" {
+		t.Errorf("Expected template file, got %v (%v)", value, err)
+	}
+	if value, err := extractStepOutput("transcript", template); err != nil || value != "docs/prd.elicitation.md" {
+		t.Errorf("Expected elicitation transcript, got %v (%v)", value, err)
+	}
+
+	checklist := &ChecklistStepOutput{Results: map[string]ChecklistItem{
+		"1": {Status: "pass"}, "2": {Status: "pass"}, "3": {Status: "fail"}, "4": {Status: "n/a"},
+	}}
+	if value, err := extractStepOutput("pass_rate", checklist); err != nil || value != 66.7 {
+		t.Errorf("Expected pass rate 66.7, got %v (%v)", value, err)
+	}
+
+	agent := &OpenCodeResult{Stdout: "thinking...\n{\"summary\": \"ok\", \"files\": [\"a.go\", \"b.go\"]}\n"}
+	if value, err := extractStepOutput("json:files.1", agent); err != nil || value != "b.go" {
+		t.Errorf("Expected JSON field b.go, got %v (%v)", value, err)
+	}
+	if _, err := extractStepOutput("json:missing", agent); err == nil {
+		t.Error("Expected an error for a missing JSON field")
+	}
+	if _, err := extractStepOutput("file", agent); err == nil {
+		t.Error("Expected an error for a template source on an agent step")
+	}
+}
+
+func TestStepOutputs_FeedLaterSteps(t *testing.T) {
+	runner, _ := newFakeOpenCodeRunner(t)
+
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	engine := &WorkflowEngine{parallelExecutor: executor, opencode: runner, strictVariables: true}
+	steps := []WorkflowStep{
+		{ID: "plan", Agent: "pm", Task: "plan", Prompt: "Write plan", Outputs: map[string]string{"summary": "stdout"}},
+		{ID: "build", Agent: "dev", Task: "build", Prompt: "Implement {{steps.plan.outputs.summary}}"},
+	}
+
+	if err := executor.ExecuteParallel(engine, steps); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+
+	summary, ok := executor.outputs.Get("plan", "summary")
+	if !ok || !strings.Contains(summary.(string), "@pm plan: Write plan") {
+		t.Fatalf("Expected plan summary output, got %v", summary)
+	}
+
+	build := executor.GetResults()[1].Output.(*OpenCodeResult)
+	if !strings.Contains(build.Stdout, "Implement fake-opencode run @pm plan: Write plan") {
+		t.Errorf("Expected build prompt to include the plan output, got %q", build.Stdout)
+	}
+}
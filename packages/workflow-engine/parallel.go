@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -13,6 +15,13 @@ type ParallelExecutionConfig struct {
 	EnableParallel  bool          `yaml:"enable_parallel,omitempty"`
 	TimeoutDuration time.Duration `yaml:"timeout_duration,omitempty"`
 	DependencyCheck bool          `yaml:"dependency_check,omitempty"`
+	// InferDependencies enables the legacy agent/variable heuristics for
+	// workflows whose steps declare no ids or depends_on lists
+	InferDependencies bool `yaml:"infer_dependencies,omitempty"`
+	// Retry is the default retry policy for every step
+	Retry *RetryPolicy `yaml:"retry,omitempty"`
+	// FailurePolicy is one of fail-fast (default), continue or skip-dependents
+	FailurePolicy string `yaml:"failure_policy,omitempty"`
 }
 
 // DefaultParallelConfig returns sensible defaults
@@ -25,9 +34,27 @@ func DefaultParallelConfig() ParallelExecutionConfig {
 	}
 }
 
+// withDefaults fills unset fields from DefaultParallelConfig. A config without
+// max_concurrency keeps the historical behaviour of enabling parallel execution.
+func (c ParallelExecutionConfig) withDefaults() ParallelExecutionConfig {
+	defaults := DefaultParallelConfig()
+
+	if c.MaxConcurrency == 0 {
+		c.MaxConcurrency = defaults.MaxConcurrency
+		c.EnableParallel = defaults.EnableParallel
+		c.DependencyCheck = defaults.DependencyCheck
+	}
+	if c.TimeoutDuration == 0 {
+		c.TimeoutDuration = defaults.TimeoutDuration
+	}
+
+	return c
+}
+
 // StepDependency represents dependencies between workflow steps
 type StepDependency struct {
 	StepIndex    int      `yaml:"step_index"`
+	ID           string   `yaml:"id"`
 	Dependencies []int    `yaml:"dependencies,omitempty"`
 	Outputs      []string `yaml:"outputs,omitempty"`
 	Inputs       []string `yaml:"inputs,omitempty"`
@@ -38,6 +65,7 @@ type DependencyGraph struct {
 	Steps         []StepDependency
 	AdjacencyList map[int][]int
 	InDegree      map[int]int
+	StepIDs       map[string]int
 }
 
 // StepResult holds the result of executing a workflow step
@@ -49,6 +77,11 @@ type StepResult struct {
 	StartTime time.Time
 	EndTime   time.Time
 	Duration  time.Duration
+	Attempts  []StepAttempt
+	// Skipped is set when the failure policy skipped the step because a
+	// step it depends on failed
+	Skipped    bool
+	SkipReason string
 }
 
 // ParallelExecutor manages parallel execution of workflow steps
@@ -64,6 +97,18 @@ type ParallelExecutor struct {
 	wg              sync.WaitGroup
 	ctx             context.Context
 	cancel          context.CancelFunc
+
+	// restored marks steps completed in a previous run (see RestoreResults)
+	restored map[int]bool
+	// onStepComplete is invoked after every executed step, e.g. to checkpoint
+	onStepComplete func(result *StepResult)
+	// outputs holds the named outputs published by completed steps
+	outputs *StepOutputs
+
+	startTime            time.Time
+	endTime              time.Time
+	criticalPath         []int
+	criticalPathDuration time.Duration
 }
 
 // ProgressUpdate represents real-time progress information
@@ -83,6 +128,8 @@ func NewParallelExecutor(config ParallelExecutionConfig) *ParallelExecutor {
 	return &ParallelExecutor{
 		config:       config,
 		stepResults:  make(map[int]*StepResult),
+		restored:     make(map[int]bool),
+		outputs:      NewStepOutputs(),
 		workerPool:   make(chan struct{}, config.MaxConcurrency),
 		resultChan:   make(chan *StepResult, 100),
 		errorChan:    make(chan error, 100),
@@ -92,47 +139,204 @@ func NewParallelExecutor(config ParallelExecutionConfig) *ParallelExecutor {
 	}
 }
 
-// BuildDependencyGraph analyzes workflow steps and builds dependency graph
+// stepID returns the declared id of a step, or a positional default
+func stepID(step WorkflowStep, index int) string {
+	if step.ID != "" {
+		return step.ID
+	}
+	return fmt.Sprintf("step-%d", index+1)
+}
+
+// usesExplicitDependencies reports whether any step declares an id or depends_on list
+func usesExplicitDependencies(steps []WorkflowStep) bool {
+	for _, step := range steps {
+		if step.ID != "" || len(step.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stepProblem is a problem with a single step; Field names the offending key
+type stepProblem struct {
+	Step    int
+	Field   string
+	Message string
+}
+
+// validateStepReferences checks step ids, depends_on and output references
+func validateStepReferences(steps []WorkflowStep) error {
+	problems := stepReferenceProblems(steps)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Message
+	}
+	return NewStepError(ErrorClassWorkflowValidation, nil, "%s", strings.Join(messages, "; "))
+}
+
+// stepReferenceProblems lists every invalid step id, depends_on entry and output reference
+func stepReferenceProblems(steps []WorkflowStep) []stepProblem {
+	ids := make(map[string]int)
+	var problems []stepProblem
+
+	for i, step := range steps {
+		id := stepID(step, i)
+		if prev, exists := ids[id]; exists {
+			problems = append(problems, stepProblem{i, "id",
+				fmt.Sprintf("step %d: duplicate id %q (already used by step %d)", i+1, id, prev+1)})
+			continue
+		}
+		ids[id] = i
+	}
+
+	for i, step := range steps {
+		id := stepID(step, i)
+		for _, dep := range step.DependsOn {
+			if dep == id {
+				problems = append(problems, stepProblem{i, "depends_on",
+					fmt.Sprintf("step %d (%s): depends on itself", i+1, id)})
+				continue
+			}
+			if _, exists := ids[dep]; !exists {
+				problems = append(problems, stepProblem{i, "depends_on",
+					fmt.Sprintf("step %d (%s): depends on unknown step id %q", i+1, id, dep)})
+			}
+		}
+
+		names := make([]string, 0, len(step.Outputs))
+		for name := range step.Outputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := validateOutputSource(step, step.Outputs[name]); err != nil {
+				problems = append(problems, stepProblem{i, "outputs",
+					fmt.Sprintf("step %d (%s): output %q: %v", i+1, id, name, err)})
+			}
+		}
+
+		problems = append(problems, thresholdProblems(steps, i, ids)...)
+
+		for _, ref := range stepOutputReferences(step) {
+			producer, exists := ids[ref.StepID]
+			switch {
+			case ref.StepID == id:
+				problems = append(problems, stepProblem{i, "prompt",
+					fmt.Sprintf("step %d (%s): references its own output %q", i+1, id, ref.Output)})
+			case !exists:
+				problems = append(problems, stepProblem{i, "prompt",
+					fmt.Sprintf("step %d (%s): references output of unknown step id %q", i+1, id, ref.StepID)})
+			default:
+				if _, declared := steps[producer].Outputs[ref.Output]; !declared {
+					problems = append(problems, stepProblem{i, "prompt",
+						fmt.Sprintf("step %d (%s): step %q does not declare output %q", i+1, id, ref.StepID, ref.Output)})
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// BuildDependencyGraph analyzes workflow steps and builds dependency graph.
+// Explicit depends_on lists are the source of truth; the legacy heuristics are
+// only applied when no step declares ids and InferDependencies is enabled.
 func (pe *ParallelExecutor) BuildDependencyGraph(steps []WorkflowStep) (*DependencyGraph, error) {
+	if err := validateStepReferences(steps); err != nil {
+		return nil, wrapStepError(ErrorClassWorkflowValidation, err, "invalid step dependencies")
+	}
+
+	graph := pe.buildGraph(steps)
+
+	// Validate graph (check for cycles)
+	if err := pe.validateDAG(graph); err != nil {
+		return nil, wrapStepError(ErrorClassWorkflowValidation, err, "dependency graph validation failed")
+	}
+
+	pe.dependencyGraph = graph
+	return graph, nil
+}
+
+// buildGraph connects steps with already validated references into a dependency graph
+func (pe *ParallelExecutor) buildGraph(steps []WorkflowStep) *DependencyGraph {
 	graph := &DependencyGraph{
 		Steps:         make([]StepDependency, len(steps)),
 		AdjacencyList: make(map[int][]int),
 		InDegree:      make(map[int]int),
+		StepIDs:       make(map[string]int),
+	}
+
+	for i, step := range steps {
+		graph.StepIDs[stepID(step, i)] = i
+		graph.InDegree[i] = 0
 	}
 
+	explicit := usesExplicitDependencies(steps)
+	remediated := remediatedSteps(steps, graph.StepIDs)
+
 	// Initialize step dependencies
 	for i, step := range steps {
 		stepDep := StepDependency{
 			StepIndex:    i,
+			ID:           stepID(step, i),
 			Dependencies: []int{},
 			Outputs:      pe.extractOutputs(step),
 			Inputs:       pe.extractInputs(step),
 		}
 
-		// Analyze dependencies based on inputs/outputs
-		for j := 0; j < i; j++ {
-			if pe.hasDependency(steps[j], step) {
+		if explicit {
+			for _, dep := range step.DependsOn {
+				j := graph.StepIDs[dep]
 				stepDep.Dependencies = append(stepDep.Dependencies, j)
 				graph.AdjacencyList[j] = append(graph.AdjacencyList[j], i)
 				graph.InDegree[i]++
 			}
+		} else if pe.config.InferDependencies {
+			// Analyze dependencies based on inputs/outputs
+			for j := 0; j < i; j++ {
+				if pe.hasDependency(steps[j], step) {
+					stepDep.Dependencies = append(stepDep.Dependencies, j)
+					graph.AdjacencyList[j] = append(graph.AdjacencyList[j], i)
+					graph.InDegree[i]++
+				}
+			}
 		}
 
-		graph.Steps[i] = stepDep
+		// Consuming another step's output implies depending on it
+		for _, ref := range stepOutputReferences(step) {
+			j := graph.StepIDs[ref.StepID]
+			if !containsStep(stepDep.Dependencies, j) {
+				stepDep.Dependencies = append(stepDep.Dependencies, j)
+				graph.AdjacencyList[j] = append(graph.AdjacencyList[j], i)
+				graph.InDegree[i]++
+			}
+		}
 
-		// Initialize in-degree for steps with no dependencies
-		if _, exists := graph.InDegree[i]; !exists {
-			graph.InDegree[i] = 0
+		// A remediation step runs after the checklist step routing to it
+		if j, ok := remediated[i]; ok && !containsStep(stepDep.Dependencies, j) {
+			stepDep.Dependencies = append(stepDep.Dependencies, j)
+			graph.AdjacencyList[j] = append(graph.AdjacencyList[j], i)
+			graph.InDegree[i]++
 		}
-	}
 
-	// Validate graph (check for cycles)
-	if err := pe.validateDAG(graph); err != nil {
-		return nil, fmt.Errorf("dependency graph validation failed: %v", err)
+		graph.Steps[i] = stepDep
 	}
 
-	pe.dependencyGraph = graph
-	return graph, nil
+	return graph
+}
+
+// containsStep reports whether a step index is in the list
+func containsStep(indices []int, stepIndex int) bool {
+	for _, index := range indices {
+		if index == stepIndex {
+			return true
+		}
+	}
+	return false
 }
 
 // extractOutputs identifies potential outputs from a workflow step
@@ -156,6 +360,11 @@ func (pe *ParallelExecutor) extractOutputs(step WorkflowStep) []string {
 		}
 	}
 
+	// Declared outputs
+	for name := range step.Outputs {
+		outputs = append(outputs, "outputs."+name)
+	}
+
 	return outputs
 }
 
@@ -175,6 +384,11 @@ func (pe *ParallelExecutor) extractInputs(step WorkflowStep) []string {
 		}
 	}
 
+	// Referenced outputs of other steps
+	for _, ref := range stepOutputReferences(step) {
+		inputs = append(inputs, fmt.Sprintf("steps.%s.outputs.%s", ref.StepID, ref.Output))
+	}
+
 	return inputs
 }
 
@@ -206,41 +420,54 @@ func (pe *ParallelExecutor) hasDependency(step1, step2 WorkflowStep) bool {
 
 // validateDAG checks for cycles in the dependency graph
 func (pe *ParallelExecutor) validateDAG(graph *DependencyGraph) error {
+	if node := pe.findCycle(graph); node >= 0 {
+		return NewStepError(ErrorClassWorkflowValidation, nil, "circular dependency detected involving step %d (%s)",
+			node+1, graph.Steps[node].ID)
+	}
+	return nil
+}
+
+// findCycle returns a step on a dependency cycle, or -1 if the graph is acyclic
+func (pe *ParallelExecutor) findCycle(graph *DependencyGraph) int {
 	visited := make(map[int]bool)
 	recStack := make(map[int]bool)
 
 	for i := 0; i < len(graph.Steps); i++ {
 		if !visited[i] {
-			if pe.hasCycle(graph, i, visited, recStack) {
-				return fmt.Errorf("circular dependency detected involving step %d", i)
+			if node := pe.cycleFrom(graph, i, visited, recStack); node >= 0 {
+				return node
 			}
 		}
 	}
 
-	return nil
+	return -1
 }
 
-// hasCycle performs DFS to detect cycles
-func (pe *ParallelExecutor) hasCycle(graph *DependencyGraph, node int, visited, recStack map[int]bool) bool {
+// cycleFrom performs DFS from node and returns a step on the first cycle found, or -1
+func (pe *ParallelExecutor) cycleFrom(graph *DependencyGraph, node int, visited, recStack map[int]bool) int {
 	visited[node] = true
 	recStack[node] = true
 
 	for _, neighbor := range graph.AdjacencyList[node] {
 		if !visited[neighbor] {
-			if pe.hasCycle(graph, neighbor, visited, recStack) {
-				return true
+			if found := pe.cycleFrom(graph, neighbor, visited, recStack); found >= 0 {
+				return found
 			}
 		} else if recStack[neighbor] {
-			return true
+			return neighbor
 		}
 	}
 
 	recStack[node] = false
-	return false
+	return -1
 }
 
 // ExecuteParallel executes workflow steps in parallel based on dependency graph
 func (pe *ParallelExecutor) ExecuteParallel(engine StepExecutor, steps []WorkflowStep) error {
+	if err := validateFailurePolicy(pe.config.FailurePolicy); err != nil {
+		return err
+	}
+
 	if !pe.config.EnableParallel {
 		return pe.executeSequential(engine, steps)
 	}
@@ -248,7 +475,7 @@ func (pe *ParallelExecutor) ExecuteParallel(engine StepExecutor, steps []Workflo
 	// Build dependency graph
 	graph, err := pe.BuildDependencyGraph(steps)
 	if err != nil {
-		return fmt.Errorf("failed to build dependency graph: %v", err)
+		return fmt.Errorf("failed to build dependency graph: %w", err)
 	}
 
 	fmt.Printf("📊 Dependency Analysis Complete:\n")
@@ -278,168 +505,316 @@ func (pe *ParallelExecutor) countParallelSteps(graph *DependencyGraph) int {
 func (pe *ParallelExecutor) executeSequential(engine StepExecutor, steps []WorkflowStep) error {
 	fmt.Printf("🔄 Sequential Execution Mode (parallel disabled)\n")
 
+	policy := pe.failurePolicy()
+	graph := pe.dependencyGraph
+	if policy == FailurePolicySkipDependents && graph == nil {
+		var err error
+		if graph, err = pe.BuildDependencyGraph(steps); err != nil {
+			return fmt.Errorf("failed to build dependency graph: %w", err)
+		}
+	}
+	blocked := make(map[int]string)
+	bypassed := make(map[int]string)
+
 	for i, step := range steps {
 		select {
 		case <-pe.ctx.Done():
-			return fmt.Errorf("execution timeout or cancelled")
+			return contextError(pe.ctx.Err(), "execution timeout or cancelled")
 		default:
+			if pe.isRestored(i) {
+				fmt.Printf("⏭️  Step %d already completed (restored from checkpoint)\n", i+1)
+				pe.restoreOutputs(step, i)
+				pe.routeRemediation(steps, i, bypassed)
+				continue
+			}
+
+			if reason, isBlocked := blocked[i]; isBlocked {
+				pe.skipStep(i, reason, false)
+				continue
+			}
+			if reason, isBypassed := bypassed[i]; isBypassed {
+				pe.skipStep(i, reason, true)
+				continue
+			}
+
 			pe.updateProgress(i, len(steps), "executing", fmt.Sprintf("Step %d: %s", i+1, step.Task))
 
-			if err := engine.executeStep(step, i+1); err != nil {
-				return fmt.Errorf("step %d failed: %v", i+1, err)
+			result := pe.runStep(engine, step, i)
+			if result.Error == nil {
+				pe.routeRemediation(steps, i, bypassed)
+				continue
+			}
+
+			switch policy {
+			case FailurePolicyFailFast:
+				return fmt.Errorf("step %d failed: %w", i+1, result.Error)
+			case FailurePolicySkipDependents:
+				pe.markDescendantsSkipped(graph, i, blocked)
 			}
 		}
 	}
 
-	return nil
+	return pe.runOutcome(len(steps))
 }
 
-// executeTopological executes steps using topological sorting for parallel execution
+// executeTopological streams steps through the dependency graph: each step is
+// dispatched as soon as its own predecessors finish, bounded by the worker pool
 func (pe *ParallelExecutor) executeTopological(engine StepExecutor, steps []WorkflowStep, graph *DependencyGraph) error {
-	ready := make([]int, 0)
 	inDegree := make(map[int]int)
-
-	// Initialize in-degree tracking
 	for k, v := range graph.InDegree {
 		inDegree[k] = v
-		if v == 0 {
-			ready = append(ready, k)
-		}
 	}
 
+	// Buffered for every step so late workers never block after we return
+	done := make(chan *StepResult, len(steps))
+	running := 0
 	completed := 0
+	policy := pe.failurePolicy()
+	blocked := make(map[int]string)
+	bypassed := make(map[int]string)
+
+	var dispatch, release func(stepIndex int)
+
+	dispatch = func(stepIndex int) {
+		// Steps restored from a checkpoint complete immediately
+		if pe.isRestored(stepIndex) {
+			fmt.Printf("⏭️  Step %d already completed (restored from checkpoint)\n", stepIndex+1)
+			pe.restoreOutputs(steps[stepIndex], stepIndex)
+			pe.routeRemediation(steps, stepIndex, bypassed)
+			completed++
+			release(stepIndex)
+			return
+		}
 
-	// Process steps level by level
-	for len(ready) > 0 || completed < len(steps) {
-		select {
-		case <-pe.ctx.Done():
-			pe.cancel()
-			return fmt.Errorf("execution timeout or cancelled")
-		default:
-			// Execute all ready steps in parallel
-			if len(ready) > 0 {
-				currentBatch := make([]int, len(ready))
-				copy(currentBatch, ready)
-				ready = ready[:0]
-
-				// Execute batch in parallel
-				if err := pe.executeBatch(engine, steps, currentBatch); err != nil {
-					return err
-				}
+		// Descendants of failed steps are skipped under skip-dependents
+		if reason, isBlocked := blocked[stepIndex]; isBlocked {
+			pe.skipStep(stepIndex, reason, false)
+			completed++
+			release(stepIndex)
+			return
+		}
 
-				// Update dependencies for completed steps
-				for _, stepIndex := range currentBatch {
-					completed++
-
-					// Reduce in-degree for dependent steps
-					for _, dependent := range graph.AdjacencyList[stepIndex] {
-						inDegree[dependent]--
-						if inDegree[dependent] == 0 {
-							ready = append(ready, dependent)
-						}
-					}
-				}
-			} else if completed < len(steps) {
-				// Wait for some steps to complete
-				time.Sleep(100 * time.Millisecond)
-
-				// Check for deadlock
-				allBlocked := true
-				for i := 0; i < len(steps); i++ {
-					if result, exists := pe.stepResults[i]; !exists || result == nil {
-						if inDegree[i] == 0 {
-							ready = append(ready, i)
-							allBlocked = false
-						}
-					}
-				}
+		// Remediation steps are bypassed when their checklist met its threshold
+		if reason, isBypassed := bypassed[stepIndex]; isBypassed {
+			pe.skipStep(stepIndex, reason, true)
+			completed++
+			release(stepIndex)
+			return
+		}
 
-				if allBlocked && len(ready) == 0 {
-					return fmt.Errorf("execution deadlock detected - no steps can proceed")
-				}
+		running++
+		pe.wg.Add(1)
+		go pe.executeStepWorker(engine, steps[stepIndex], stepIndex, done)
+	}
+
+	// release unblocks dependents whose predecessors have all finished
+	release = func(stepIndex int) {
+		for _, dependent := range graph.AdjacencyList[stepIndex] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				dispatch(dependent)
 			}
 		}
 	}
 
-	return nil
-}
+	// Collect the initial ready set before dispatching: a restored step releases
+	// its dependents synchronously, and they must not be dispatched twice
+	pe.startTime = time.Now()
+	var ready []int
+	for i := 0; i < len(steps); i++ {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for _, stepIndex := range ready {
+		dispatch(stepIndex)
+	}
 
-// executeBatch executes a batch of ready steps in parallel
-func (pe *ParallelExecutor) executeBatch(engine StepExecutor, steps []WorkflowStep, batch []int) error {
-	batchSize := len(batch)
-	if batchSize == 0 {
-		return nil
+	// stop cancels the steps still in flight and waits for their workers, so
+	// nothing is left running when the caller exits
+	stop := func(err error) error {
+		pe.cancel()
+		pe.wg.Wait()
+		return err
 	}
 
-	fmt.Printf("⚡ Executing batch of %d parallel steps: %v\n", batchSize, batch)
+	for completed < len(steps) {
+		if running == 0 {
+			return NewStepError(ErrorClassWorkflowValidation, nil, "execution deadlock detected - no steps can proceed")
+		}
 
-	// Use worker pool to limit concurrency
-	for _, stepIndex := range batch {
-		pe.wg.Add(1)
-		go pe.executeStepWorker(engine, steps[stepIndex], stepIndex)
+		select {
+		case <-pe.ctx.Done():
+			return stop(contextError(pe.ctx.Err(), "execution timeout or cancelled"))
+		case result := <-done:
+			running--
+			completed++
+
+			if result.Error != nil {
+				switch policy {
+				case FailurePolicyFailFast:
+					return stop(fmt.Errorf("step %d failed: %w", result.StepIndex+1, result.Error))
+				case FailurePolicySkipDependents:
+					pe.markDescendantsSkipped(graph, result.StepIndex, blocked)
+				}
+			} else {
+				pe.routeRemediation(steps, result.StepIndex, bypassed)
+			}
+
+			release(result.StepIndex)
+		}
 	}
 
-	// Wait for all steps in batch to complete with timeout check
-	done := make(chan struct{})
-	go func() {
-		pe.wg.Wait()
-		close(done)
-	}()
+	pe.endTime = time.Now()
+	pe.computeCriticalPath(graph)
+	return pe.runOutcome(len(steps))
+}
 
-	select {
-	case <-done:
-		// All steps completed
-	case <-pe.ctx.Done():
-		// Timeout occurred
-		return fmt.Errorf("batch execution timeout")
+// computeCriticalPath records the longest chain of dependent steps by duration
+func (pe *ParallelExecutor) computeCriticalPath(graph *DependencyGraph) {
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	finish := make(map[int]time.Duration)
+	prev := make(map[int]int)
+
+	var longest func(node int) time.Duration
+	longest = func(node int) time.Duration {
+		if d, ok := finish[node]; ok {
+			return d
+		}
+
+		prev[node] = -1
+		var best time.Duration
+		for _, dep := range graph.Steps[node].Dependencies {
+			if d := longest(dep); d > best || prev[node] == -1 {
+				best = d
+				prev[node] = dep
+			}
+		}
+
+		var own time.Duration
+		if result, exists := pe.stepResults[node]; exists {
+			own = result.Duration
+		}
+
+		finish[node] = best + own
+		return finish[node]
 	}
 
-	// Check for errors in batch
-	for _, stepIndex := range batch {
-		if result, exists := pe.stepResults[stepIndex]; exists && result.Error != nil {
-			return fmt.Errorf("step %d failed: %v", stepIndex+1, result.Error)
+	end := -1
+	for i := range graph.Steps {
+		if d := longest(i); end == -1 || d > finish[end] {
+			end = i
 		}
 	}
 
-	return nil
+	pe.criticalPath = nil
+	pe.criticalPathDuration = 0
+	if end == -1 {
+		return
+	}
+
+	pe.criticalPathDuration = finish[end]
+	for node := end; node != -1; node = prev[node] {
+		pe.criticalPath = append([]int{node}, pe.criticalPath...)
+	}
+}
+
+// CriticalPath returns the step indices on the critical path and its total duration
+func (pe *ParallelExecutor) CriticalPath() ([]int, time.Duration) {
+	pe.mutex.RLock()
+	defer pe.mutex.RUnlock()
+
+	path := make([]int, len(pe.criticalPath))
+	copy(path, pe.criticalPath)
+	return path, pe.criticalPathDuration
 }
 
 // StepExecutor interface for testing
 type StepExecutor interface {
-	executeStep(step WorkflowStep, stepIndex int) error
+	executeStep(step WorkflowStep, stepIndex int) (interface{}, error)
 }
 
-// executeStepWorker executes a single step in a goroutine
-func (pe *ParallelExecutor) executeStepWorker(engine StepExecutor, step WorkflowStep, stepIndex int) {
+// executeStepWorker executes a single step in a goroutine and reports on done
+func (pe *ParallelExecutor) executeStepWorker(engine StepExecutor, step WorkflowStep, stepIndex int, done chan<- *StepResult) {
 	defer pe.wg.Done()
 
-	// Check for cancellation before starting
+	// Acquire worker slot unless execution is cancelled first
 	select {
 	case <-pe.ctx.Done():
-		pe.mutex.Lock()
-		pe.stepResults[stepIndex] = &StepResult{
+		result := &StepResult{
 			StepIndex: stepIndex,
 			Success:   false,
-			Error:     fmt.Errorf("execution cancelled"),
+			Error:     contextError(pe.ctx.Err(), "execution cancelled"),
 			StartTime: time.Now(),
 			EndTime:   time.Now(),
 			Duration:  0,
 		}
+		pe.mutex.Lock()
+		pe.stepResults[stepIndex] = result
 		pe.mutex.Unlock()
+		done <- result
 		return
-	default:
+	case pe.workerPool <- struct{}{}:
 	}
-
-	// Acquire worker slot
-	pe.workerPool <- struct{}{}
 	defer func() { <-pe.workerPool }()
 
+	done <- pe.runStep(engine, step, stepIndex)
+}
+
+// runStep executes a single step, stores its result and notifies the completion hook
+func (pe *ParallelExecutor) runStep(engine StepExecutor, step WorkflowStep, stepIndex int) *StepResult {
 	startTime := time.Now()
 
 	pe.updateProgress(stepIndex, -1, "executing", fmt.Sprintf("Step %d: %s", stepIndex+1, step.Task))
 
-	// Execute step with error handling
-	err := engine.executeStep(step, stepIndex+1)
+	// Execute step with error handling, retrying per the step's policy
+	policy := pe.retryPolicyFor(step)
+	var output interface{}
+	var err error
+	var attempts []StepAttempt
+
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		output, err = engine.executeStep(step, stepIndex+1)
+
+		record := StepAttempt{
+			Number:    attempt,
+			Error:     err,
+			StartTime: attemptStart,
+			Duration:  time.Since(attemptStart),
+		}
+		if err != nil {
+			record.ErrorClass = ErrorClassOf(err)
+		}
+
+		if !policy.shouldRetry(err, attempt) {
+			attempts = append(attempts, record)
+			break
+		}
+
+		record.Delay = policy.backoff(attempt)
+		attempts = append(attempts, record)
+
+		pe.updateProgress(stepIndex, -1, "retrying", fmt.Sprintf("Step %d attempt %d/%d failed (%s), retrying in %v: %v",
+			stepIndex+1, attempt, policy.MaxAttempts, record.ErrorClass, record.Delay, err))
+
+		cancelled := false
+		select {
+		case <-time.After(record.Delay):
+		case <-pe.ctx.Done():
+			cancelled = true
+		}
+		if cancelled {
+			break
+		}
+	}
+
+	// Publish declared outputs for later steps
+	if err == nil {
+		err = pe.recordOutputs(step, stepIndex, output)
+	}
 
 	endTime := time.Now()
 	duration := endTime.Sub(startTime)
@@ -449,9 +824,11 @@ func (pe *ParallelExecutor) executeStepWorker(engine StepExecutor, step Workflow
 		StepIndex: stepIndex,
 		Success:   err == nil,
 		Error:     err,
+		Output:    output,
 		StartTime: startTime,
 		EndTime:   endTime,
 		Duration:  duration,
+		Attempts:  attempts,
 	}
 
 	pe.mutex.Lock()
@@ -464,6 +841,33 @@ func (pe *ParallelExecutor) executeStepWorker(engine StepExecutor, step Workflow
 	} else {
 		pe.updateProgress(stepIndex, -1, "completed", fmt.Sprintf("Step %d completed in %v", stepIndex+1, duration))
 	}
+
+	if pe.onStepComplete != nil {
+		pe.onStepComplete(result)
+	}
+
+	return result
+}
+
+// RestoreResults seeds the executor with results from a previous run.
+// Successful steps are skipped when the workflow executes again.
+func (pe *ParallelExecutor) RestoreResults(results map[int]*StepResult) {
+	pe.mutex.Lock()
+	defer pe.mutex.Unlock()
+
+	for stepIndex, result := range results {
+		pe.stepResults[stepIndex] = result
+		if result.Success {
+			pe.restored[stepIndex] = true
+		}
+	}
+}
+
+// isRestored reports whether a step completed in a previous run
+func (pe *ParallelExecutor) isRestored(stepIndex int) bool {
+	pe.mutex.RLock()
+	defer pe.mutex.RUnlock()
+	return pe.restored[stepIndex]
 }
 
 // updateProgress sends progress updates
@@ -528,11 +932,96 @@ func (pe *ParallelExecutor) GetResults() map[int]*StepResult {
 // Cleanup releases resources
 func (pe *ParallelExecutor) Cleanup() {
 	pe.cancel()
+	// In-flight workers may still report progress after a timeout
+	pe.wg.Wait()
 	close(pe.resultChan)
 	close(pe.errorChan)
 	close(pe.progressChan)
 }
 
+// printRetrySummary lists steps that needed more than one attempt; callers hold the mutex
+func (pe *ParallelExecutor) printRetrySummary() {
+	indices := make([]int, 0, len(pe.stepResults))
+	for stepIndex, result := range pe.stepResults {
+		if len(result.Attempts) > 1 {
+			indices = append(indices, stepIndex)
+		}
+	}
+	if len(indices) == 0 {
+		return
+	}
+	sort.Ints(indices)
+
+	extra := 0
+	for _, stepIndex := range indices {
+		extra += len(pe.stepResults[stepIndex].Attempts) - 1
+	}
+	fmt.Printf("   🔁 Retried Steps: %d (%d extra attempts)\n", len(indices), extra)
+
+	for _, stepIndex := range indices {
+		result := pe.stepResults[stepIndex]
+		fmt.Printf("      Step %d: %d attempts\n", stepIndex+1, len(result.Attempts))
+		for _, attempt := range result.Attempts {
+			if attempt.Error != nil {
+				fmt.Printf("         #%d ❌ %s after %v: %v\n", attempt.Number, attempt.ErrorClass, attempt.Duration, attempt.Error)
+			} else {
+				fmt.Printf("         #%d ✅ succeeded after %v\n", attempt.Number, attempt.Duration)
+			}
+		}
+	}
+}
+
+// printSkipSummary lists skipped steps with their reasons; callers hold the mutex
+func (pe *ParallelExecutor) printSkipSummary() {
+	indices := make([]int, 0)
+	for stepIndex, result := range pe.stepResults {
+		if result.Skipped {
+			indices = append(indices, stepIndex)
+		}
+	}
+	sort.Ints(indices)
+
+	for _, stepIndex := range indices {
+		fmt.Printf("      Step %d skipped: %s\n", stepIndex+1, pe.stepResults[stepIndex].SkipReason)
+	}
+}
+
+// printFailureSummary lists failed steps grouped by error class with their remediation
+func (pe *ParallelExecutor) printFailureSummary() {
+	byClass := make(map[ErrorClass][]int)
+	for stepIndex, result := range pe.stepResults {
+		if !result.Success && !result.Skipped && result.Error != nil {
+			class := ErrorClassOf(result.Error)
+			byClass[class] = append(byClass[class], stepIndex)
+		}
+	}
+	if len(byClass) == 0 {
+		return
+	}
+
+	classes := make([]string, 0, len(byClass))
+	for class := range byClass {
+		classes = append(classes, string(class))
+	}
+	sort.Strings(classes)
+
+	fmt.Printf("   🧯 Failures by class:\n")
+	for _, class := range classes {
+		indices := byClass[ErrorClass(class)]
+		sort.Ints(indices)
+
+		fmt.Printf("      %s (%d):\n", class, len(indices))
+		for _, stepIndex := range indices {
+			err := pe.stepResults[stepIndex].Error
+			severity, remediation := describeError(err)
+			fmt.Printf("        Step %d [%s/%s]: %v\n", stepIndex+1, class, severity, err)
+			if remediation != "" {
+				fmt.Printf("          💡 %s\n", remediation)
+			}
+		}
+	}
+}
+
 // PrintExecutionSummary displays execution statistics
 func (pe *ParallelExecutor) PrintExecutionSummary() {
 	pe.mutex.RLock()
@@ -544,14 +1033,24 @@ func (pe *ParallelExecutor) PrintExecutionSummary() {
 	}
 
 	successCount := 0
+	failedCount := 0
+	skippedCount := 0
+	executedCount := 0
 	totalDuration := time.Duration(0)
 	var minDuration, maxDuration time.Duration
 
 	for _, result := range pe.stepResults {
-		if result.Success {
+		switch {
+		case result.Skipped:
+			skippedCount++
+			continue
+		case result.Success:
 			successCount++
+		default:
+			failedCount++
 		}
 
+		executedCount++
 		totalDuration += result.Duration
 
 		if minDuration == 0 || result.Duration < minDuration {
@@ -563,14 +1062,39 @@ func (pe *ParallelExecutor) PrintExecutionSummary() {
 		}
 	}
 
-	avgDuration := totalDuration / time.Duration(totalSteps)
+	avgDuration := time.Duration(0)
+	if executedCount > 0 {
+		avgDuration = totalDuration / time.Duration(executedCount)
+	}
 
 	fmt.Printf("\n📈 Parallel Execution Summary:\n")
 	fmt.Printf("   ✅ Success Rate: %d/%d (%.1f%%)\n",
 		successCount, totalSteps, float64(successCount)/float64(totalSteps)*100)
+	if failedCount > 0 || skippedCount > 0 {
+		fmt.Printf("   ❌ Failed: %d   ⏭️  Skipped: %d\n", failedCount, skippedCount)
+	}
 	fmt.Printf("   ⏱️  Total Duration: %v\n", totalDuration)
 	fmt.Printf("   📊 Average Duration: %v\n", avgDuration)
 	fmt.Printf("   ⚡ Fastest Step: %v\n", minDuration)
 	fmt.Printf("   🐌 Slowest Step: %v\n", maxDuration)
 	fmt.Printf("   🔧 Concurrency Used: %d\n", pe.config.MaxConcurrency)
+
+	pe.printRetrySummary()
+	pe.printSkipSummary()
+	pe.printFailureSummary()
+
+	if !pe.startTime.IsZero() && !pe.endTime.IsZero() {
+		fmt.Printf("   🕒 Wall Time: %v\n", pe.endTime.Sub(pe.startTime))
+	}
+
+	if len(pe.criticalPath) > 0 {
+		labels := make([]string, len(pe.criticalPath))
+		for i, stepIndex := range pe.criticalPath {
+			labels[i] = fmt.Sprintf("%d", stepIndex+1)
+			if pe.dependencyGraph != nil && stepIndex < len(pe.dependencyGraph.Steps) {
+				labels[i] = pe.dependencyGraph.Steps[stepIndex].ID
+			}
+		}
+		fmt.Printf("   🛤️  Critical Path: %s (%v)\n", strings.Join(labels, " → "), pe.criticalPathDuration)
+	}
 }
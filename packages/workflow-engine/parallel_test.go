@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -12,12 +16,12 @@ type MockWorkflowEngine struct {
 	executeFunc func(WorkflowStep, int) error
 }
 
-func (m *MockWorkflowEngine) executeStep(step WorkflowStep, stepNum int) error {
+func (m *MockWorkflowEngine) executeStep(step WorkflowStep, stepNum int) (interface{}, error) {
 	if m.executeFunc != nil {
-		return m.executeFunc(step, stepNum)
+		return nil, m.executeFunc(step, stepNum)
 	}
 	// Default implementation - just return success
-	return nil
+	return nil, nil
 }
 
 func TestDefaultParallelConfig(t *testing.T) {
@@ -90,6 +94,7 @@ func TestBuildDependencyGraph_NoDependencies(t *testing.T) {
 
 func TestBuildDependencyGraph_WithDependencies(t *testing.T) {
 	config := DefaultParallelConfig()
+	config.InferDependencies = true
 	executor := NewParallelExecutor(config)
 	defer executor.Cleanup()
 
@@ -115,6 +120,154 @@ func TestBuildDependencyGraph_WithDependencies(t *testing.T) {
 	}
 }
 
+func TestBuildDependencyGraph_InferenceIsOptIn(t *testing.T) {
+	config := DefaultParallelConfig()
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	steps := []WorkflowStep{
+		{Agent: "architect", Task: "design"},
+		{Agent: "dev", Task: "implement", Variables: map[string]interface{}{"requires": "architecture"}},
+	}
+
+	graph, err := executor.BuildDependencyGraph(steps)
+	if err != nil {
+		t.Fatalf("Failed to build dependency graph: %v", err)
+	}
+
+	if graph.InDegree[1] != 0 {
+		t.Errorf("Heuristics should not apply without infer_dependencies, in-degree: %d", graph.InDegree[1])
+	}
+}
+
+func TestShippedWorkflows_KeepStepOrder(t *testing.T) {
+	// Steps that build on an earlier step's document declare it explicitly
+	expected := map[string]map[int]int{
+		"epic-2-demonstration.yaml":   {0: 0, 1: 1, 2: 1, 3: 2},
+		"create-simple-doc.yaml":      {0: 0, 1: 1, 2: 1},
+		"complex-multi-step.yaml":     {0: 0, 1: 1, 2: 1, 3: 1, 4: 2},
+		"create-doc-interactive.yaml": {0: 0, 1: 1},
+	}
+
+	for file, inDegrees := range expected {
+		t.Run(file, func(t *testing.T) {
+			workflow, err := loadWorkflow("../../bmad-core/workflows/" + file)
+			if err != nil {
+				t.Fatalf("Failed to load workflow: %v", err)
+			}
+
+			executor := NewParallelExecutor(workflow.Parallel.withDefaults())
+			defer executor.Cleanup()
+
+			graph, err := executor.BuildDependencyGraph(workflow.Steps)
+			if err != nil {
+				t.Fatalf("Failed to build dependency graph: %v", err)
+			}
+			for step, inDegree := range inDegrees {
+				if graph.InDegree[step] != inDegree {
+					t.Errorf("Step %d: expected in-degree %d, got %d", step+1, inDegree, graph.InDegree[step])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildDependencyGraph_ExplicitDependsOn(t *testing.T) {
+	config := DefaultParallelConfig()
+	config.InferDependencies = true
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	steps := []WorkflowStep{
+		{ID: "design", Agent: "architect", Task: "design"},
+		{ID: "requirements", Agent: "po", Task: "requirements"},
+		{ID: "build", Agent: "dev", Task: "implement", DependsOn: []string{"design"}},
+		{ID: "test", Agent: "qa", Task: "validate", DependsOn: []string{"build", "requirements"}},
+	}
+
+	graph, err := executor.BuildDependencyGraph(steps)
+	if err != nil {
+		t.Fatalf("Failed to build dependency graph: %v", err)
+	}
+
+	// Explicit ids win over heuristics: po -> dev is not inferred
+	expected := map[int]int{0: 0, 1: 0, 2: 1, 3: 2}
+	for step, inDegree := range expected {
+		if graph.InDegree[step] != inDegree {
+			t.Errorf("Step %d: expected in-degree %d, got %d", step, inDegree, graph.InDegree[step])
+		}
+	}
+
+	if graph.StepIDs["build"] != 2 {
+		t.Errorf("Expected id 'build' to map to step 2, got %d", graph.StepIDs["build"])
+	}
+
+	if graph.Steps[3].ID != "test" {
+		t.Errorf("Expected step 3 id 'test', got %q", graph.Steps[3].ID)
+	}
+}
+
+func TestBuildDependencyGraph_ExplicitCycle(t *testing.T) {
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	steps := []WorkflowStep{
+		{ID: "a", DependsOn: []string{"b"}},
+		{ID: "b", DependsOn: []string{"a"}},
+	}
+
+	if _, err := executor.BuildDependencyGraph(steps); err == nil {
+		t.Error("Expected circular dependency error, but got none")
+	}
+}
+
+func TestValidateStepReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []WorkflowStep
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			steps: []WorkflowStep{{ID: "a"}, {ID: "b", DependsOn: []string{"a"}}},
+		},
+		{
+			name:  "default ids",
+			steps: []WorkflowStep{{}, {DependsOn: []string{"step-1"}}},
+		},
+		{
+			name:    "unknown id",
+			steps:   []WorkflowStep{{ID: "a", DependsOn: []string{"missing"}}},
+			wantErr: `unknown step id "missing"`,
+		},
+		{
+			name:    "self reference",
+			steps:   []WorkflowStep{{ID: "a", DependsOn: []string{"a"}}},
+			wantErr: "depends on itself",
+		},
+		{
+			name:    "duplicate id",
+			steps:   []WorkflowStep{{ID: "a"}, {ID: "a"}},
+			wantErr: `duplicate id "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStepReferences(tt.steps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestBuildDependencyGraph_CircularDependency(t *testing.T) {
 	config := DefaultParallelConfig()
 	executor := NewParallelExecutor(config)
@@ -366,6 +519,95 @@ func TestConcurrentStepExecution(t *testing.T) {
 	}
 }
 
+func TestStreamingScheduler_DispatchesDependentsEarly(t *testing.T) {
+	config := ParallelExecutionConfig{
+		EnableParallel:  true,
+		MaxConcurrency:  4,
+		TimeoutDuration: 5 * time.Second,
+	}
+
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	var mu sync.Mutex
+	started := make(map[string]time.Time)
+	finished := make(map[string]time.Time)
+
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			mu.Lock()
+			started[step.ID] = time.Now()
+			mu.Unlock()
+
+			if step.ID == "slow" {
+				time.Sleep(300 * time.Millisecond)
+			} else {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			mu.Lock()
+			finished[step.ID] = time.Now()
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	// "follow-up" only depends on "fast", so it must not wait for "slow"
+	steps := []WorkflowStep{
+		{ID: "slow", Agent: "architect"},
+		{ID: "fast", Agent: "po"},
+		{ID: "follow-up", Agent: "sm", DependsOn: []string{"fast"}},
+	}
+
+	if err := executor.ExecuteParallel(mockEngine, steps); err != nil {
+		t.Fatalf("Parallel execution failed: %v", err)
+	}
+
+	if !started["follow-up"].Before(finished["slow"]) {
+		t.Error("Dependent step waited for an unrelated slow step")
+	}
+
+	if started["follow-up"].Before(finished["fast"]) {
+		t.Error("Dependent step started before its predecessor finished")
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	executor := NewParallelExecutor(DefaultParallelConfig())
+	defer executor.Cleanup()
+
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			if step.ID == "design" {
+				time.Sleep(150 * time.Millisecond)
+			} else {
+				time.Sleep(10 * time.Millisecond)
+			}
+			return nil
+		},
+	}
+
+	steps := []WorkflowStep{
+		{ID: "design"},
+		{ID: "requirements"},
+		{ID: "build", DependsOn: []string{"design", "requirements"}},
+		{ID: "docs", DependsOn: []string{"requirements"}},
+	}
+
+	if err := executor.ExecuteParallel(mockEngine, steps); err != nil {
+		t.Fatalf("Parallel execution failed: %v", err)
+	}
+
+	path, duration := executor.CriticalPath()
+	if len(path) != 2 || path[0] != 0 || path[1] != 2 {
+		t.Errorf("Expected critical path [0 2], got %v", path)
+	}
+
+	if duration < 150*time.Millisecond {
+		t.Errorf("Expected critical path duration >= 150ms, got %v", duration)
+	}
+}
+
 func TestTimeout(t *testing.T) {
 	config := ParallelExecutionConfig{
 		EnableParallel:  true,
@@ -482,3 +724,39 @@ func BenchmarkParallelExecution(b *testing.B) {
 		executor.Cleanup()
 	}
 }
+
+func TestExecuteParallel_CancelDuringPrompt(t *testing.T) {
+	for _, enableParallel := range []bool{true, false} {
+		t.Run(fmt.Sprintf("parallel=%t", enableParallel), func(t *testing.T) {
+			config := DefaultParallelConfig()
+			config.EnableParallel = enableParallel
+			executor := NewParallelExecutor(config)
+			defer executor.Cleanup()
+
+			// Nothing is ever typed, as with a user sitting at the prompt
+			input, _ := io.Pipe()
+			defer input.Close()
+			dp := &DocumentProcessor{reader: bufio.NewReader(input), ctx: executor.ctx}
+
+			mockEngine := &MockWorkflowEngine{
+				executeFunc: func(step WorkflowStep, stepNum int) error {
+					_, err := dp.getUserInput("Project name:")
+					return err
+				},
+			}
+
+			time.AfterFunc(50*time.Millisecond, executor.cancel)
+			errs := make(chan error, 1)
+			go func() { errs <- executor.ExecuteParallel(mockEngine, []WorkflowStep{{ID: "brief"}}) }()
+
+			select {
+			case err := <-errs:
+				if ErrorClassOf(err) != ErrorClassCancelled {
+					t.Errorf("Expected a cancelled error, got %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Expected cancellation to interrupt the prompt")
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathConfig configures where template and checklist files are searched for
+// and where generated files are written
+type PathConfig struct {
+	// SearchRoots are searched after the workflow file's directory, e.g. an
+	// expansion-pack directory. Relative roots are relative to the workflow file.
+	SearchRoots []string `yaml:"search_roots,omitempty"`
+	// ProjectRoot is the directory output filenames are resolved against
+	// (default: the working directory)
+	ProjectRoot string `yaml:"project_root,omitempty"`
+}
+
+// PathResolver resolves template and checklist paths relative to the workflow
+// file, the configured search roots, the nearest bmad-core directory (and the
+// project containing it) and the project root, in that order
+type PathResolver struct {
+	workflowDir string
+	roots       []string
+	projectRoot string
+}
+
+// NewPathResolver creates a resolver for a workflow file. Roots and the project
+// root given on the command line are relative to the working directory and
+// take precedence over those configured in the workflow, which are relative
+// to the workflow file.
+func NewPathResolver(workflowFile string, config PathConfig, extraRoots []string, projectRoot string) *PathResolver {
+	workflowDir := filepath.Dir(absPath(workflowFile))
+	r := &PathResolver{workflowDir: workflowDir}
+
+	for _, root := range extraRoots {
+		r.addRoot(absPath(root))
+	}
+	for _, root := range config.SearchRoots {
+		r.addRoot(relativeTo(workflowDir, root))
+	}
+	// The nearest bmad-core resolves "templates/x.yaml"; its parent resolves "bmad-core/templates/x.yaml"
+	if core := findBmadCoreDir(workflowDir); core != "" {
+		r.addRoot(core)
+		r.addRoot(filepath.Dir(core))
+	}
+
+	switch {
+	case projectRoot != "":
+		r.projectRoot = absPath(projectRoot)
+	case config.ProjectRoot != "":
+		r.projectRoot = relativeTo(workflowDir, config.ProjectRoot)
+	default:
+		r.projectRoot = absPath(".")
+	}
+
+	return r
+}
+
+// addRoot appends a search root unless it is already present
+func (r *PathResolver) addRoot(root string) {
+	for _, existing := range r.roots {
+		if existing == root {
+			return
+		}
+	}
+	r.roots = append(r.roots, root)
+}
+
+// Candidates returns the locations searched for a path, in order
+func (r *PathResolver) Candidates(path string) []string {
+	if filepath.IsAbs(path) {
+		return []string{path}
+	}
+
+	candidates := []string{filepath.Join(r.workflowDir, path)}
+	for _, root := range r.roots {
+		candidates = append(candidates, filepath.Join(root, path))
+	}
+	return append(candidates, filepath.Join(r.projectRoot, path))
+}
+
+// Resolve returns the absolute path of the first existing candidate for path
+func (r *PathResolver) Resolve(path string) (string, error) {
+	candidates := r.Candidates(path)
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", NewStepError(ErrorClassFilesystem, nil, "file %q not found (searched %s)", path, strings.Join(candidates, ", ")).
+		WithRemediation("Fix the path or add its directory with paths.search_roots or --search-root")
+}
+
+// ResolveOutput returns the absolute path of a generated file
+func (r *PathResolver) ResolveOutput(filename string) string {
+	if filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(r.projectRoot, filename)
+}
+
+// ResolveDocument returns the path of a document a step works on. Documents
+// are usually generated by earlier steps, so the output location under the
+// project root is checked before the search candidates.
+func (r *PathResolver) ResolveDocument(path string) (string, error) {
+	output := r.ResolveOutput(path)
+	if _, err := os.Stat(output); err == nil {
+		return output, nil
+	}
+	return r.Resolve(path)
+}
+
+// ProjectRoot returns the directory output files are written under
+func (r *PathResolver) ProjectRoot() string {
+	return r.projectRoot
+}
+
+// Roots returns the configured and discovered search roots
+func (r *PathResolver) Roots() []string {
+	return append([]string{}, r.roots...)
+}
+
+// findBmadCoreDir walks up from dir looking for a bmad-core directory
+func findBmadCoreDir(dir string) string {
+	dir = absPath(dir)
+	for {
+		if filepath.Base(dir) == "bmad-core" {
+			return dir
+		}
+
+		candidate := filepath.Join(dir, "bmad-core")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// relativeTo resolves a relative path against base
+func relativeTo(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// absPath returns path made absolute, or path unchanged if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates empty files (and their directories) under root
+func writeFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPathResolver_SearchOrder(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root,
+		"bmad-core/workflows/wf.yaml",
+		"bmad-core/templates/prd-tmpl.yaml",
+		"bmad-core/templates/local-tmpl.yaml",
+		"bmad-core/workflows/templates/local-tmpl.yaml",
+		"expansion/templates/game-tmpl.yaml",
+		"expansion/templates/prd-tmpl.yaml",
+	)
+	workflowFile := filepath.Join(root, "bmad-core", "workflows", "wf.yaml")
+
+	resolver := NewPathResolver(workflowFile, PathConfig{SearchRoots: []string{"../../expansion"}}, nil, root)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		// Relative to the workflow file wins
+		{"templates/local-tmpl.yaml", "bmad-core/workflows/templates/local-tmpl.yaml"},
+		{"../templates/prd-tmpl.yaml", "bmad-core/templates/prd-tmpl.yaml"},
+		// Configured roots are searched before bmad-core
+		{"templates/prd-tmpl.yaml", "expansion/templates/prd-tmpl.yaml"},
+		{"templates/game-tmpl.yaml", "expansion/templates/game-tmpl.yaml"},
+		// Paths written relative to the project containing bmad-core
+		{"bmad-core/templates/prd-tmpl.yaml", "bmad-core/templates/prd-tmpl.yaml"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolver.Resolve(tt.path)
+		if err != nil {
+			t.Errorf("Resolve(%q) failed: %v", tt.path, err)
+			continue
+		}
+		if want := filepath.Join(root, tt.want); got != want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.path, got, want)
+		}
+	}
+}
+
+func TestPathResolver_CommandLineRootsFirst(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "pack/checklists/c.md", "wf/checklists/c.md", "override/checklists/c.md")
+
+	resolver := NewPathResolver(filepath.Join(root, "wf", "wf.yaml"), PathConfig{SearchRoots: []string{"../pack"}},
+		[]string{filepath.Join(root, "override")}, "")
+
+	// The workflow directory still wins over every root
+	if got, _ := resolver.Resolve("checklists/c.md"); got != filepath.Join(root, "wf", "checklists", "c.md") {
+		t.Errorf("Expected the workflow-relative file, got %s", got)
+	}
+
+	roots := resolver.Roots()
+	if len(roots) < 2 || roots[0] != filepath.Join(root, "override") || roots[1] != filepath.Join(root, "pack") {
+		t.Errorf("Expected command-line root before configured root, got %v", roots)
+	}
+}
+
+func TestPathResolver_NotFound(t *testing.T) {
+	root := t.TempDir()
+	resolver := NewPathResolver(filepath.Join(root, "wf.yaml"), PathConfig{}, nil, root)
+
+	_, err := resolver.Resolve("templates/missing.yaml")
+	if err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+	if ErrorClassOf(err) != ErrorClassFilesystem || !strings.Contains(err.Error(), filepath.Join(root, "templates", "missing.yaml")) {
+		t.Errorf("Expected a filesystem error listing the searched paths, got %v", err)
+	}
+}
+
+func TestPathResolver_ResolveOutput(t *testing.T) {
+	root := t.TempDir()
+
+	configured := NewPathResolver(filepath.Join(root, "workflows", "wf.yaml"), PathConfig{ProjectRoot: ".."}, nil, "")
+	if got := configured.ResolveOutput("docs/prd.md"); got != filepath.Join(root, "docs", "prd.md") {
+		t.Errorf("Expected output under the configured project root, got %s", got)
+	}
+
+	override := NewPathResolver(filepath.Join(root, "workflows", "wf.yaml"), PathConfig{ProjectRoot: ".."}, nil, filepath.Join(root, "out"))
+	if got := override.ResolveOutput("docs/prd.md"); got != filepath.Join(root, "out", "docs", "prd.md") {
+		t.Errorf("Expected --project-root to win, got %s", got)
+	}
+
+	if got := override.ResolveOutput("/abs/prd.md"); got != "/abs/prd.md" {
+		t.Errorf("Absolute output paths should be kept, got %s", got)
+	}
+}
+
+func TestPathResolver_ResolveDocument(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "workflows/wf.yaml", "workflows/docs/prd.md", "out/docs/prd.md", "workflows/docs/brief.md")
+	resolver := NewPathResolver(filepath.Join(root, "workflows", "wf.yaml"), PathConfig{}, nil, filepath.Join(root, "out"))
+
+	// The generated document wins over a same-named file next to the workflow
+	if got, _ := resolver.ResolveDocument("docs/prd.md"); got != filepath.Join(root, "out", "docs", "prd.md") {
+		t.Errorf("Expected the document under the project root, got %s", got)
+	}
+	if got, _ := resolver.ResolveDocument("docs/brief.md"); got != filepath.Join(root, "workflows", "docs", "brief.md") {
+		t.Errorf("Expected the search candidates as a fallback, got %s", got)
+	}
+	if _, err := resolver.ResolveDocument("docs/missing.md"); ErrorClassOf(err) != ErrorClassFilesystem {
+		t.Errorf("Expected a filesystem error for a missing document, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+)
+
+// newTestScope returns a scope over variables that ignores the environment
+func newTestScope(strict bool, variables map[string]interface{}) *VariableScope {
+	scope := NewVariableScope(strict, variables)
+	scope.env = nil
+	return scope
+}
+
+// newTestProcessor returns a DocumentProcessor over variables that reads the
+// user's answers from input
+func newTestProcessor(strict bool, variables map[string]interface{}, input string) *DocumentProcessor {
+	return &DocumentProcessor{reader: bufio.NewReader(strings.NewReader(input)), scope: newTestScope(strict, variables)}
+}
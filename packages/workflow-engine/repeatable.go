@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// numberSuffix marks title placeholders such as {{epic_number}} that are
+// filled with the instance number when nothing else defines them
+const numberSuffix = "_number"
+
+// variableScope returns the scope sections are currently rendered in
+func (dp *DocumentProcessor) variableScope() *VariableScope {
+	if dp.scope == nil {
+		dp.scope = NewVariableScope(false)
+	}
+	return dp.scope
+}
+
+// processRepeatable renders every instance of a repeatable section. Instances
+// come from a list variable named after the section id (e.g. `epic-details`),
+// whose entries are maps of per-instance variables. Without one, interactive
+// runs collect instances until the user declines to add another and yolo runs
+// render a single instance.
+func (dp *DocumentProcessor) processRepeatable(section TemplateSection, depth int, interactive bool) error {
+	// The instances of a repeatable list are its items, rendered under one heading
+	if hasItemTemplate(section) {
+		section.Repeatable = false
+		if interactive {
+			return dp.processSectionInteractive(section, depth)
+		}
+		return dp.processSectionYolo(section, depth)
+	}
+
+	items, found, err := dp.repeatableItems(section)
+	if err != nil {
+		return err
+	}
+
+	if found || !interactive {
+		if !found {
+			fmt.Printf("   ⚠️  No %q list variable; rendering one instance of %q\n", section.ID, section.Title)
+			items = []map[string]interface{}{{}}
+		}
+		fmt.Printf("   🔁 Repeating %q for %d instance(s)\n", section.ID, len(items))
+		for i, item := range items {
+			if err := dp.processInstance(section, depth, i+1, item, interactive); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for number := 1; ; number++ {
+		fmt.Printf("   🔁 %s #%d\n", section.ID, number)
+		item, err := dp.promptInstance(section, number)
+		if err != nil {
+			return err
+		}
+		if err := dp.processInstance(section, depth, number, item, true); err != nil {
+			return err
+		}
+
+		more, err := dp.askYesNo(fmt.Sprintf("➕ Add another %s", section.ID))
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// repeatableItems returns the instances listed in the section's list variable.
+// The value may be a list of maps or, when passed with --var, a JSON array.
+func (dp *DocumentProcessor) repeatableItems(section TemplateSection) ([]map[string]interface{}, bool, error) {
+	value, ok := dp.variableScope().Lookup(section.ID)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if text, isString := value.(string); isString {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			return nil, false, NewStepError(ErrorClassWorkflowValidation, err, "variable %q for repeatable section is not a JSON list", section.ID)
+		}
+		value = decoded
+	}
+
+	list, isList := value.([]interface{})
+	if !isList {
+		return nil, false, NewStepError(ErrorClassWorkflowValidation, nil, "variable %q for repeatable section must be a list, got %T", section.ID, value)
+	}
+
+	items := make([]map[string]interface{}, 0, len(list))
+	for i, entry := range list {
+		item, isMap := entry.(map[string]interface{})
+		if !isMap {
+			return nil, false, NewStepError(ErrorClassWorkflowValidation, nil, "entry %d of %q must be a map of instance variables, got %T", i+1, section.ID, entry)
+		}
+		items = append(items, item)
+	}
+	return items, true, nil
+}
+
+// promptInstance asks for the title variables of one instance that are not
+// already defined; number placeholders are filled in automatically
+func (dp *DocumentProcessor) promptInstance(section TemplateSection, number int) (map[string]interface{}, error) {
+	item := map[string]interface{}{}
+	scope := dp.variableScope()
+
+	for _, name := range placeholderNames(section.Title) {
+		if strings.HasSuffix(name, numberSuffix) {
+			continue
+		}
+		if _, defined := scope.Lookup(name); defined {
+			continue
+		}
+
+		value, err := dp.getUserInput(fmt.Sprintf("Enter %s for %s #%d:", name, section.ID, number))
+		if err != nil {
+			return nil, err
+		}
+		item[name] = value
+	}
+	return item, nil
+}
+
+// processInstance renders one instance of a repeatable section with its
+// instance variables layered over the current scope. Its variables and the
+// choices made inside it end with the instance, so every instance asks again.
+func (dp *DocumentProcessor) processInstance(section TemplateSection, depth int, number int, item map[string]interface{}, interactive bool) error {
+	parent := dp.variableScope()
+	choices := len(dp.choices)
+	dp.instances++
+	defer func() {
+		dp.scope = parent
+		dp.choices = dp.choices[:choices]
+		dp.instances--
+	}()
+
+	dp.scope = parent.with(dp.instanceLayer(section.Title, number, item))
+	return dp.renderInstance(section, depth, number, interactive)
+}
+
+func (dp *DocumentProcessor) renderInstance(section TemplateSection, depth int, number int, interactive bool) error {
+	instance := section
+	instance.Repeatable = false
+	resolved, err := dp.scope.resolveSections([]TemplateSection{instance})
+	if err != nil {
+		return err
+	}
+	instance = resolved[0]
+	if !hasNumberPlaceholder(section.Title) {
+		instance.Title = fmt.Sprintf("%s %d", instance.Title, number)
+	}
+
+	if interactive {
+		return dp.processSectionInteractive(instance, depth)
+	}
+	return dp.processSectionYolo(instance, depth)
+}
+
+// instanceLayer returns an instance's variables, with the number placeholders
+// of text that nothing else defines set to the instance number
+func (dp *DocumentProcessor) instanceLayer(text string, number int, item map[string]interface{}) map[string]interface{} {
+	layer := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		layer[name] = value
+	}
+	for _, name := range placeholderNames(text) {
+		if !strings.HasSuffix(name, numberSuffix) {
+			continue
+		}
+		if _, defined := dp.variableScope().with(layer).Lookup(name); !defined {
+			layer[name] = number
+		}
+	}
+	return layer
+}
+
+// hasNumberPlaceholder reports whether text has a {{..._number}} placeholder
+func hasNumberPlaceholder(text string) bool {
+	for _, name := range placeholderNames(text) {
+		if strings.HasSuffix(name, numberSuffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasItemTemplate reports whether a section's items are rendered through its
+// item_template; only list sections have items
+func hasItemTemplate(section TemplateSection) bool {
+	return section.ItemTemplate != "" && isListType(section.Type)
+}
+
+// renderItems writes a list section's items through its item_template, e.g.
+// "{{criterion_number}}: {{criteria}}". Items come from a list variable named
+// after the section id. Without one, interactive runs ask for items until the
+// first placeholder is left empty, and yolo runs keep two items as a scaffold.
+func (dp *DocumentProcessor) renderItems(section TemplateSection, interactive bool) error {
+	items, found, err := dp.repeatableItems(section)
+	if err != nil {
+		return err
+	}
+
+	if !found && !interactive {
+		items = []map[string]interface{}{{}, {}}
+	}
+	if !found && interactive {
+		itemSection := section
+		itemSection.Template = section.ItemTemplate
+		for number := 1; ; number++ {
+			values, done, err := dp.promptPlaceholders(itemSection, number)
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+			items = append(items, values)
+		}
+	}
+
+	for i, item := range items {
+		line, err := dp.lenientScope().with(dp.instanceLayer(section.ItemTemplate, i+1, item)).Interpolate(section.ItemTemplate)
+		if err != nil {
+			return err
+		}
+		dp.addToOutput(line)
+	}
+	dp.addToOutput("")
+	return nil
+}
+
+// placeholderNames returns the distinct variable names used in text, in order
+func placeholderNames(text string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
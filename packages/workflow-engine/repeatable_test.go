@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func newRepeatableTemplate() Template {
+	var template Template
+	template.Template.Name = "PRD"
+	template.Template.Output.Title = "PRD"
+	template.Sections = []TemplateSection{
+		{
+			ID:         "epic-details",
+			Title:      "Epic {{epic_number}} {{epic_title}}",
+			Repeatable: true,
+			Sections: []TemplateSection{
+				{
+					ID:         "story",
+					Title:      "Story {{epic_number}}.{{story_number}} {{story_title}}",
+					Repeatable: true,
+				},
+			},
+		},
+	}
+	return template
+}
+
+func headings(document []string) []string {
+	var result []string
+	for _, line := range document {
+		if strings.HasPrefix(line, "## ") || strings.HasPrefix(line, "### ") {
+			result = append(result, strings.TrimLeft(line, "# "))
+		}
+	}
+	return result
+}
+
+func TestProcessTemplate_RepeatableFromListVariable(t *testing.T) {
+	epics := []interface{}{
+		map[string]interface{}{
+			"epic_title": "Foundation",
+			"story": []interface{}{
+				map[string]interface{}{"story_title": "Setup"},
+				map[string]interface{}{"story_title": "CI"},
+			},
+		},
+		map[string]interface{}{
+			"epic_title": "Payments",
+			"story":      []interface{}{map[string]interface{}{"story_title": "Checkout"}},
+		},
+	}
+
+	dp := newTestProcessor(true, map[string]interface{}{"epic-details": epics}, "")
+
+	if err := dp.processTemplate(newRepeatableTemplate(), "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"Epic 1 Foundation",
+		"Story 1.1 Setup",
+		"Story 1.2 CI",
+		"Epic 2 Payments",
+		"Story 2.1 Checkout",
+	}
+	if got := headings(dp.output); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected headings %v, got %v", want, got)
+	}
+}
+
+func TestProcessTemplate_RepeatableFromJSONVariable(t *testing.T) {
+	var template Template
+	template.Template.Output.Title = "Stories"
+	template.Sections = []TemplateSection{{ID: "risk", Title: "Risk", Repeatable: true}}
+
+	dp := newTestProcessor(false, map[string]interface{}{"risk": `[{}, {}]`}, "")
+
+	if err := dp.processTemplate(template, "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := headings(dp.output); strings.Join(got, "|") != "Risk 1|Risk 2" {
+		t.Errorf("Expected numbered instances without a number placeholder, got %v", got)
+	}
+
+	dp.scope = newTestScope(false, map[string]interface{}{"risk": "not a list"})
+	if err := dp.processTemplate(template, "yolo"); ErrorClassOf(err) != ErrorClassWorkflowValidation {
+		t.Errorf("Expected a workflow-validation error for a non-list variable, got %v", err)
+	}
+}
+
+func TestProcessTemplate_RepeatableInteractive(t *testing.T) {
+	template := newRepeatableTemplate()
+	template.Sections[0].Sections[0].Sections = nil
+
+	// Epic 1 with two stories, epic 2 with one; each section asks for content
+	input := strings.Join([]string{
+		"Foundation", "epic one",
+		"Setup", "story one", "y",
+		"CI", "story two", "n",
+		"y",
+		"Payments", "epic two",
+		"Checkout", "story three", "n",
+		"n",
+	}, "\n") + "\n"
+
+	dp := newTestProcessor(false, nil, input)
+
+	if err := dp.processTemplate(template, "interactive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"Epic 1 Foundation",
+		"Story 1.1 Setup",
+		"Story 1.2 CI",
+		"Epic 2 Payments",
+		"Story 2.1 Checkout",
+	}
+	if got := headings(dp.output); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected headings %v, got %v", want, got)
+	}
+	if !strings.Contains(strings.Join(dp.output, "\n"), "story three") {
+		t.Error("Expected per-instance content in the document")
+	}
+}
+
+func newCriteriaTemplate() Template {
+	var template Template
+	template.Template.Output.Title = "Story"
+	template.Sections = []TemplateSection{{
+		ID:           "acceptance-criteria",
+		Title:        "Acceptance Criteria",
+		Type:         "numbered-list",
+		ItemTemplate: "{{criterion_number}}: {{criteria}}",
+		Repeatable:   true,
+	}}
+	return template
+}
+
+func TestProcessTemplate_ItemTemplate(t *testing.T) {
+	criteria := []interface{}{
+		map[string]interface{}{"criteria": "Users can sign up"},
+		map[string]interface{}{"criteria": "Users can sign in"},
+	}
+	want := "## Acceptance Criteria\n\n1: Users can sign up\n2: Users can sign in\n"
+
+	dp := newTestProcessor(true, map[string]interface{}{"acceptance-criteria": criteria}, "")
+	if err := dp.processTemplate(newCriteriaTemplate(), "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if document := strings.Join(dp.output, "\n"); !strings.Contains(document, want) {
+		t.Errorf("Expected the list variable's items under one heading:\n%s", document)
+	}
+
+	// Interactive runs ask for items until the first placeholder is left empty
+	dp = newTestProcessor(false, nil, "Users can sign up\nUsers can sign in\n\n")
+	if err := dp.processTemplate(newCriteriaTemplate(), "interactive"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if document := strings.Join(dp.output, "\n"); !strings.Contains(document, want) {
+		t.Errorf("Expected the entered items under one heading:\n%s", document)
+	}
+}
+
+func TestProcessTemplate_RepeatableScopesInstanceChoices(t *testing.T) {
+	var template Template
+	template.Template.Output.Title = "Risks"
+	template.Sections = []TemplateSection{
+		{
+			ID:         "risk",
+			Title:      "Risk {{risk_title}}",
+			Repeatable: true,
+			Sections: []TemplateSection{
+				{ID: "level", Title: "Level", Type: "choice", Choices: TemplateChoices{"risk_level": {"Low", "High"}}},
+			},
+		},
+		{ID: "summary", Title: "Summary ({{risk_level}})"},
+	}
+
+	risks := []interface{}{
+		map[string]interface{}{"risk_title": "Outage"},
+		map[string]interface{}{"risk_title": "Data loss"},
+	}
+	dp := newTestProcessor(false, map[string]interface{}{"risk": risks}, "")
+
+	if err := dp.processTemplate(template, "yolo"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "Risk Outage 1|Level|Risk Data loss 2|Level|Summary ({{risk_level}})"
+	if got := headings(dp.output); strings.Join(got, "|") != want {
+		t.Errorf("Expected headings %s, got %v", want, got)
+	}
+
+	// Each instance makes its own choice, which neither leaks into later
+	// sections nor is kept as an answer for the step
+	document := strings.Join(dp.output, "\n")
+	if count := strings.Count(document, "risk_level defaulted"); count != 2 {
+		t.Errorf("Expected the choice to be made once per instance, got %d:\n%s", count, document)
+	}
+	if _, ok := dp.answers["risk_level"]; ok {
+		t.Errorf("Expected instance choices to stay out of the step's answers, got %v", dp.answers)
+	}
+}
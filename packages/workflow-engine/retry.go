@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy controls automatic retries of failed steps. It can be set as the
+// workflow default under `parallel.retry` and overridden per step with `retry`.
+type RetryPolicy struct {
+	MaxAttempts int           `yaml:"max_attempts,omitempty"`
+	BaseDelay   time.Duration `yaml:"base_delay,omitempty"`
+	MaxDelay    time.Duration `yaml:"max_delay,omitempty"`
+	Jitter      float64       `yaml:"jitter,omitempty"`   // fraction of each delay that is randomized (0-1)
+	RetryOn     []string      `yaml:"retry_on,omitempty"` // retryable error classes; empty retries any failure
+}
+
+// StepAttempt records a single execution attempt of a step
+type StepAttempt struct {
+	Number     int
+	Error      error
+	ErrorClass ErrorClass
+	StartTime  time.Time
+	Duration   time.Duration
+	Delay      time.Duration // backoff waited before the next attempt
+}
+
+// mergeRetryPolicy overlays the fields set on override onto base
+func mergeRetryPolicy(base, override *RetryPolicy) RetryPolicy {
+	var policy RetryPolicy
+	if base != nil {
+		policy = *base
+	}
+	if override == nil {
+		return policy
+	}
+
+	if override.MaxAttempts != 0 {
+		policy.MaxAttempts = override.MaxAttempts
+	}
+	if override.BaseDelay != 0 {
+		policy.BaseDelay = override.BaseDelay
+	}
+	if override.MaxDelay != 0 {
+		policy.MaxDelay = override.MaxDelay
+	}
+	if override.Jitter != 0 {
+		policy.Jitter = override.Jitter
+	}
+	if len(override.RetryOn) > 0 {
+		policy.RetryOn = override.RetryOn
+	}
+
+	return policy
+}
+
+// retryPolicyFor returns the effective retry policy for a step
+func (pe *ParallelExecutor) retryPolicyFor(step WorkflowStep) RetryPolicy {
+	policy := mergeRetryPolicy(pe.config.Retry, step.Retry)
+
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultRetryMaxDelay
+	}
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	}
+	if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+
+	return policy
+}
+
+// shouldRetry reports whether a failed attempt may be retried under the policy
+func (p RetryPolicy) shouldRetry(err error, attempt int) bool {
+	if err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	class := ErrorClassOf(err)
+	if class == ErrorClassCancelled || class == ErrorClassWorkflowValidation {
+		return false
+	}
+
+	// Checklists that missed their threshold are only retried when asked to
+	if len(p.RetryOn) == 0 {
+		return class != ErrorClassThreshold
+	}
+
+	for _, retryable := range p.RetryOn {
+		if ErrorClass(retryable) == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given attempt is retried: exponential
+// growth from BaseDelay capped at MaxDelay, with up to Jitter of it randomized
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	delay -= delay * p.Jitter * rand.Float64()
+	return time.Duration(delay)
+}
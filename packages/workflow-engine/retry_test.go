@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMergeRetryPolicy(t *testing.T) {
+	base := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, RetryOn: []string{"agent"}}
+	override := &RetryPolicy{MaxAttempts: 5, Jitter: 0.5}
+
+	policy := mergeRetryPolicy(base, override)
+
+	if policy.MaxAttempts != 5 {
+		t.Errorf("Expected step override of MaxAttempts, got %d", policy.MaxAttempts)
+	}
+	if policy.BaseDelay != time.Second {
+		t.Errorf("Expected BaseDelay inherited from default, got %v", policy.BaseDelay)
+	}
+	if policy.Jitter != 0.5 {
+		t.Errorf("Expected Jitter 0.5, got %v", policy.Jitter)
+	}
+	if len(policy.RetryOn) != 1 || policy.RetryOn[0] != "agent" {
+		t.Errorf("Expected RetryOn inherited from default, got %v", policy.RetryOn)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("Attempt %d: expected delay %v, got %v", i+1, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.backoff(2)
+		if delay < 100*time.Millisecond || delay > 200*time.Millisecond {
+			t.Fatalf("Jittered delay %v outside [100ms, 200ms]", delay)
+		}
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, RetryOn: []string{"agent", "timeout"}}
+
+	agentErr := NewStepError(ErrorClassAgent, nil, "opencode exited with code 1")
+	templateErr := NewStepError(ErrorClassTemplate, nil, "error reading template file x.yaml")
+
+	if !policy.shouldRetry(agentErr, 1) {
+		t.Error("Agent failures should be retried")
+	}
+	if policy.shouldRetry(agentErr, 3) {
+		t.Error("Should not retry once MaxAttempts is reached")
+	}
+	if policy.shouldRetry(templateErr, 1) {
+		t.Error("Template failures are not in retry_on and should not be retried")
+	}
+	if policy.shouldRetry(fmt.Errorf("wrapped: %w", context.Canceled), 1) {
+		t.Error("Cancelled steps should never be retried")
+	}
+	if !policy.shouldRetry(fmt.Errorf("step 2: %w", agentErr), 1) {
+		t.Error("Wrapped agent failures should keep their class and be retried")
+	}
+
+	policy.RetryOn = nil
+	if !policy.shouldRetry(templateErr, 1) {
+		t.Error("Empty retry_on should retry any failure")
+	}
+}
+
+func TestRunStep_RetriesUntilSuccess(t *testing.T) {
+	config := DefaultParallelConfig()
+	config.Retry = &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	var calls int32
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return NewStepError(ErrorClassAgent, nil, "opencode exited with code 1")
+			}
+			return nil
+		},
+	}
+
+	// The step-level policy raises the workflow default of 2 attempts to 3
+	steps := []WorkflowStep{
+		{ID: "flaky", Retry: &RetryPolicy{MaxAttempts: 3}},
+	}
+
+	if err := executor.ExecuteParallel(mockEngine, steps); err != nil {
+		t.Fatalf("Expected flaky step to succeed after retries, got %v", err)
+	}
+
+	result := executor.GetResults()[0]
+	if len(result.Attempts) != 3 {
+		t.Fatalf("Expected 3 recorded attempts, got %d", len(result.Attempts))
+	}
+
+	first := result.Attempts[0]
+	if first.Error == nil || first.ErrorClass != "agent" || first.Delay == 0 {
+		t.Errorf("First attempt not recorded correctly: %+v", first)
+	}
+
+	if last := result.Attempts[2]; last.Error != nil || last.Delay != 0 {
+		t.Errorf("Final attempt should be a success without delay: %+v", last)
+	}
+
+	executor.PrintExecutionSummary()
+}
+
+func TestRunStep_GivesUpAfterMaxAttempts(t *testing.T) {
+	config := DefaultParallelConfig()
+	config.Retry = &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	var calls int32
+	mockEngine := &MockWorkflowEngine{
+		executeFunc: func(step WorkflowStep, stepNum int) error {
+			atomic.AddInt32(&calls, 1)
+			return NewStepError(ErrorClassAgent, nil, "opencode exited with code 1")
+		},
+	}
+
+	err := executor.ExecuteParallel(mockEngine, []WorkflowStep{{ID: "broken"}})
+	if err == nil {
+		t.Fatal("Expected failure after exhausting retries")
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestLoadWorkflow_RetryBlocks(t *testing.T) {
+	path := writeTestWorkflow(t, t.TempDir(), `name: retry
+parallel:
+  retry:
+    max_attempts: 3
+    base_delay: 2s
+    max_delay: 1m
+    jitter: 0.2
+    retry_on: [agent, timeout]
+steps:
+  - id: generate
+    agent: dev
+    retry:
+      max_attempts: 5
+`)
+
+	workflow, err := loadWorkflow(path)
+	if err != nil {
+		t.Fatalf("Failed to load workflow: %v", err)
+	}
+
+	config := workflow.Parallel.withDefaults()
+	if !config.EnableParallel || config.MaxConcurrency != 4 {
+		t.Errorf("Expected defaults for unset parallel fields, got %+v", config)
+	}
+
+	executor := NewParallelExecutor(config)
+	defer executor.Cleanup()
+
+	policy := executor.retryPolicyFor(workflow.Steps[0])
+	if policy.MaxAttempts != 5 || policy.BaseDelay != 2*time.Second || policy.MaxDelay != time.Minute {
+		t.Errorf("Unexpected effective policy: %+v", policy)
+	}
+	if policy.Jitter != 0.2 || len(policy.RetryOn) != 2 {
+		t.Errorf("Unexpected effective policy: %+v", policy)
+	}
+}
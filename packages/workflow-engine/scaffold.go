@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hasTemplateBody reports whether a section is rendered from its `template:`
+// body; mermaid, table and choice sections use the body their own way
+func hasTemplateBody(section TemplateSection) bool {
+	switch section.Type {
+	case "mermaid", "table", "choice":
+		return false
+	}
+	return section.Template != ""
+}
+
+// isListType reports whether a section's template body is a per-item template
+func isListType(sectionType string) bool {
+	return sectionType == "bullet-list" || sectionType == "numbered-list"
+}
+
+// renderTemplateBody renders a section's `template:` body. Interactive runs ask
+// only for the placeholders the body contains that are not already defined;
+// list sections repeat the body per item until the first placeholder is left
+// empty. Yolo runs fill what the variables define and keep the remaining
+// placeholders as a scaffold.
+func (dp *DocumentProcessor) renderTemplateBody(section TemplateSection, interactive bool) error {
+	if !interactive {
+		body, err := dp.lenientScope().Interpolate(section.Template)
+		if err != nil {
+			return err
+		}
+		dp.addBody(section, 1, body)
+		dp.addToOutput("")
+		return nil
+	}
+
+	for number := 1; ; number++ {
+		values, done, err := dp.promptPlaceholders(section, number)
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+
+		body, err := dp.lenientScope().with(dp.instanceLayer(section.Template, number, values)).Interpolate(section.Template)
+		if err != nil {
+			return err
+		}
+		dp.addBody(section, number, body)
+
+		if !isListType(section.Type) {
+			break
+		}
+	}
+	dp.addToOutput("")
+	return nil
+}
+
+// promptPlaceholders asks for the body's undefined placeholders, once each;
+// number placeholders are filled in with the item number instead. For list
+// items, an empty first answer ends the list.
+func (dp *DocumentProcessor) promptPlaceholders(section TemplateSection, number int) (map[string]interface{}, bool, error) {
+	values := map[string]interface{}{}
+	scope := dp.variableScope()
+
+	asked := 0
+	for _, name := range placeholderNames(section.Template) {
+		if _, defined := scope.Lookup(name); defined || strings.HasSuffix(name, numberSuffix) {
+			continue
+		}
+
+		prompt := fmt.Sprintf("Enter %s:", name)
+		if isListType(section.Type) {
+			prompt = fmt.Sprintf("Enter %s for item %d (empty to finish):", name, number)
+			if asked > 0 {
+				prompt = fmt.Sprintf("Enter %s for item %d:", name, number)
+			}
+		}
+
+		value, err := dp.getUserInput(prompt)
+		if err != nil {
+			return nil, false, err
+		}
+		if asked == 0 && value == "" && isListType(section.Type) {
+			return nil, true, nil
+		}
+		values[name] = value
+		if !isListType(section.Type) {
+			dp.recordAnswer(name, value)
+		}
+		asked++
+	}
+
+	// A list body without placeholders is a single fixed item
+	if asked == 0 && isListType(section.Type) && number > 1 {
+		return nil, true, nil
+	}
+	return values, false, nil
+}
+
+// addBody writes a filled body: list items get their marker, code is fenced
+func (dp *DocumentProcessor) addBody(section TemplateSection, number int, body string) {
+	body = strings.TrimRight(body, "\n")
+
+	switch section.Type {
+	case "code":
+		dp.addToOutput("```" + section.Language)
+		for _, line := range strings.Split(body, "\n") {
+			dp.addToOutput(line)
+		}
+		dp.addToOutput("```")
+	case "numbered-list":
+		lines := strings.Split(body, "\n")
+		dp.addToOutput(numberedListItem(section.Prefix, number, lines[0]))
+		for _, line := range lines[1:] {
+			dp.addToOutput("   " + line)
+		}
+	case "bullet-list":
+		lines := strings.Split(body, "\n")
+		if !strings.HasPrefix(lines[0], "- ") && !strings.HasPrefix(lines[0], "* ") {
+			lines[0] = "- " + lines[0]
+			for i := 1; i < len(lines); i++ {
+				lines[i] = "  " + lines[i]
+			}
+		}
+		for _, line := range lines {
+			dp.addToOutput(line)
+		}
+	default:
+		for _, line := range strings.Split(body, "\n") {
+			dp.addToOutput(line)
+		}
+	}
+}
+
+// lenientScope returns the current scope without strict checking: template
+// bodies are content scaffolds, so their unfilled placeholders are kept
+func (dp *DocumentProcessor) lenientScope() *VariableScope {
+	lenient := *dp.variableScope()
+	lenient.strict = false
+	return &lenient
+}
+
+// showExamples prints a section's examples as hints for the user
+func (dp *DocumentProcessor) showExamples(section TemplateSection) {
+	for _, example := range section.Examples {
+		lines := strings.Split(strings.TrimRight(example, "\n"), "\n")
+		fmt.Printf("   💡 Example: %s\n", lines[0])
+		for _, line := range lines[1:] {
+			fmt.Printf("               %s\n", line)
+		}
+	}
+}
+
+// addExampleGuidance writes a section's examples as an HTML comment, so yolo
+// drafts carry the guidance without it rendering
+func (dp *DocumentProcessor) addExampleGuidance(section TemplateSection) {
+	if len(section.Examples) == 0 {
+		return
+	}
+
+	dp.addToOutput("<!-- Examples:")
+	for _, example := range section.Examples {
+		for _, line := range strings.Split(strings.TrimRight(example, "\n"), "\n") {
+			dp.addToOutput(strings.ReplaceAll(line, "--", "- -"))
+		}
+	}
+	dp.addToOutput("-->")
+	dp.addToOutput("")
+}
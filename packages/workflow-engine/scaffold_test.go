@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func renderScaffold(t *testing.T, section TemplateSection, variables map[string]interface{}, mode, input string, examples bool) (string, *bufio.Reader) {
+	t.Helper()
+
+	dp := newTestProcessor(true, variables, input)
+	dp.examples = examples
+
+	var err error
+	if mode == "interactive" {
+		err = dp.processSectionInteractive(section, 0)
+	} else {
+		err = dp.processSectionYolo(section, 0)
+	}
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return strings.Join(dp.output, "\n"), dp.reader
+}
+
+func TestTemplateBody_PromptsOnlyForUndefinedPlaceholders(t *testing.T) {
+	section := TemplateSection{
+		ID:       "summary",
+		Title:    "Summary",
+		Type:     "paragraphs",
+		Template: "**Topic:** {{session_topic}}\n\n**Goals:** {{stated_goals}}",
+	}
+
+	got, reader := renderScaffold(t, section, map[string]interface{}{"session_topic": "Onboarding"}, "interactive", "Fewer drop-offs\nleftover\n", false)
+
+	if !strings.Contains(got, "**Topic:** Onboarding\n\n**Goals:** Fewer drop-offs") {
+		t.Errorf("Expected the filled body:\n%s", got)
+	}
+	if rest, _ := reader.ReadString('\n'); rest != "leftover\n" {
+		t.Errorf("Expected a single prompt, but input %q was consumed", "leftover")
+	}
+}
+
+func TestTemplateBody_RepeatsListItems(t *testing.T) {
+	section := TemplateSection{
+		ID:       "immediate",
+		Title:    "Immediate Opportunities",
+		Type:     "numbered-list",
+		Template: "**{{idea_name}}**\n- Why immediate: {{rationale}}",
+	}
+
+	got, _ := renderScaffold(t, section, nil, "interactive", "Self-serve signup\nLow effort\nReferral bonus\nCheap\n\n", false)
+
+	want := "1. **Self-serve signup**\n   - Why immediate: Low effort\n2. **Referral bonus**\n   - Why immediate: Cheap"
+	if !strings.Contains(got, want) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestTemplateBody_YoloKeepsScaffoldAndExamples(t *testing.T) {
+	section := TemplateSection{
+		ID:       "rest-api",
+		Title:    "REST API Spec",
+		Type:     "code",
+		Language: "yaml",
+		Template: "openapi: 3.0.0\ninfo:\n  title: {{api_title}}\n  version: {{api_version}}",
+		Examples: []string{"title: Orders API -- v2"},
+	}
+
+	got, _ := renderScaffold(t, section, map[string]interface{}{"api_title": "Orders"}, "yolo", "", true)
+
+	if !strings.Contains(got, "```yaml\nopenapi: 3.0.0\ninfo:\n  title: Orders\n  version: {{api_version}}\n```") {
+		t.Errorf("Expected a fenced scaffold keeping the undefined placeholder:\n%s", got)
+	}
+	if !strings.Contains(got, "<!-- Examples:\ntitle: Orders API - - v2\n-->") {
+		t.Errorf("Expected the examples as a guidance comment:\n%s", got)
+	}
+
+	got, _ = renderScaffold(t, section, nil, "yolo", "", false)
+	if strings.Contains(got, "<!--") {
+		t.Errorf("Expected no guidance comment unless include_examples is set:\n%s", got)
+	}
+}
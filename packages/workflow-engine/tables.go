@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// columnKeyPattern matches the characters dropped when matching a column to a map key
+var columnKeyPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// TableRow is a row prefilled by a template: either plain cells or a category
+// heading that groups rows, as in competitor-analysis-tmpl.yaml
+type TableRow struct {
+	Cells    []string
+	Category string
+	Items    [][]string
+}
+
+// UnmarshalYAML accepts both `["A", "B"]` and `{category: X, items: [[...]]}` rows
+func (r *TableRow) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		return node.Decode(&r.Cells)
+	}
+
+	var group struct {
+		Category string     `yaml:"category"`
+		Items    [][]string `yaml:"items"`
+	}
+	if err := node.Decode(&group); err != nil {
+		return err
+	}
+	r.Category, r.Items = group.Category, group.Items
+	return nil
+}
+
+// generateTable renders a table section. Rows come from a list variable named
+// after the section id, else the template's prefilled rows; interactive runs
+// can then add rows of their own.
+func (dp *DocumentProcessor) generateTable(section TemplateSection, interactive bool) error {
+	scope := dp.variableScope()
+
+	columns := append([]string(nil), section.Columns...)
+	for i := range columns {
+		column, err := scope.Interpolate(columns[i])
+		if err != nil {
+			return err
+		}
+		columns[i] = column
+	}
+
+	rows, err := dp.tableRows(section, columns)
+	if err != nil {
+		return err
+	}
+
+	if interactive {
+		added, err := dp.getTableRows(columns, rows)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, added...)
+	}
+
+	if len(columns) == 0 {
+		width := 2
+		for _, row := range rows {
+			if len(row) > width {
+				width = len(row)
+			}
+		}
+		for i := 1; i <= width; i++ {
+			columns = append(columns, fmt.Sprintf("Column %d", i))
+		}
+	}
+	if len(rows) == 0 {
+		row := make([]string, len(columns))
+		for i := range row {
+			row[i] = "_TBD_"
+		}
+		rows = append(rows, row)
+	}
+
+	dp.addToOutput(tableLine(columns))
+	separator := make([]string, len(columns))
+	for i := range separator {
+		separator[i] = "----------"
+	}
+	dp.addToOutput("|" + strings.Join(separator, "|") + "|")
+	for _, row := range rows {
+		dp.addToOutput(tableLine(row))
+	}
+	dp.addToOutput("")
+	return nil
+}
+
+// tableRows returns the rows supplied by a variable or prefilled in the template
+func (dp *DocumentProcessor) tableRows(section TemplateSection, columns []string) ([][]string, error) {
+	scope := dp.variableScope()
+
+	if value, ok := scope.Lookup(section.ID); ok {
+		list, isList := value.([]interface{})
+		if !isList {
+			return nil, NewStepError(ErrorClassUserInput, nil, "rows for table %q must be a list, got %T", section.ID, value)
+		}
+
+		var rows [][]string
+		for i, entry := range list {
+			row, err := variableTableRow(entry, columns)
+			if err != nil {
+				return nil, NewStepError(ErrorClassUserInput, err, "row %d of table %q", i+1, section.ID)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+
+	var cells [][]string
+	for _, row := range section.Rows {
+		if row.Category == "" {
+			cells = append(cells, row.Cells)
+			continue
+		}
+		heading := make([]string, len(columns))
+		if len(heading) == 0 {
+			heading = []string{""}
+		}
+		heading[0] = "**" + row.Category + "**"
+		cells = append(cells, heading)
+		cells = append(cells, row.Items...)
+	}
+
+	rows := make([][]string, 0, len(cells))
+	for i, row := range cells {
+		if len(columns) > 0 && len(row) != len(columns) {
+			return nil, NewStepError(ErrorClassTemplate, nil, "row %d of table %q has %d cells, expected %d",
+				i+1, section.ID, len(row), len(columns))
+		}
+		interpolated := make([]string, len(row))
+		for j, cell := range row {
+			value, err := scope.Interpolate(cell)
+			if err != nil {
+				return nil, err
+			}
+			interpolated[j] = value
+		}
+		rows = append(rows, interpolated)
+	}
+	return rows, nil
+}
+
+// variableTableRow converts a row given in a variable: a list of cells or a
+// map from column name (or its snake_case form) to cell
+func variableTableRow(entry interface{}, columns []string) ([]string, error) {
+	switch row := entry.(type) {
+	case []interface{}:
+		if len(columns) > 0 && len(row) != len(columns) {
+			return nil, fmt.Errorf("has %d cells, expected %d", len(row), len(columns))
+		}
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprintf("%v", cell)
+		}
+		return cells, nil
+	case map[string]interface{}:
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			for key, value := range row {
+				if columnKey(key) == columnKey(column) {
+					cells[i] = fmt.Sprintf("%v", value)
+				}
+			}
+		}
+		return cells, nil
+	default:
+		return nil, fmt.Errorf("must be a list of cells or a map of column values, got %T", entry)
+	}
+}
+
+// columnKey normalizes a column name so "Hex Code" matches hex_code
+func columnKey(name string) string {
+	return strings.Trim(columnKeyPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// getTableRows reads rows typed as `cell | cell | ...` until an empty line,
+// re-asking when the cell count does not match the columns
+func (dp *DocumentProcessor) getTableRows(columns []string, existing [][]string) ([][]string, error) {
+	if len(existing) > 0 {
+		fmt.Printf("   📋 %d prefilled row(s)\n", len(existing))
+	}
+	if len(columns) > 0 {
+		fmt.Printf("   Columns: %s\n", strings.Join(columns, " | "))
+	}
+
+	var rows [][]string
+	for {
+		input, err := dp.getUserInput("Enter row as `cell | cell | ...` (empty line to finish):")
+		if err != nil {
+			return nil, err
+		}
+		if input == "" {
+			return rows, nil
+		}
+
+		cells := splitTableRow(input)
+		if len(columns) > 0 && len(cells) != len(columns) {
+			fmt.Printf("   ⚠️  Expected %d cells, got %d; use \\| for a literal pipe\n", len(columns), len(cells))
+			continue
+		}
+		rows = append(rows, cells)
+	}
+}
+
+// splitTableRow splits typed input on unescaped pipes, ignoring outer pipes
+func splitTableRow(input string) []string {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "|") {
+		input = input[1:]
+	}
+	if strings.HasSuffix(input, "|") && !strings.HasSuffix(input, `\|`) {
+		input = input[:len(input)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(input); i++ {
+		switch {
+		case input[i] == '\\' && i+1 < len(input) && input[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case input[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(input[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// tableLine formats cells as a markdown table row
+func tableLine(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeTableCell(cell)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// escapeTableCell escapes pipes and newlines so a cell cannot break the table
+func escapeTableCell(cell string) string {
+	var b strings.Builder
+	for i := 0; i < len(cell); i++ {
+		if cell[i] == '|' && (i == 0 || cell[i-1] != '\\') {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(cell[i])
+	}
+	return strings.ReplaceAll(strings.TrimSpace(b.String()), "\n", "<br>")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func renderTable(t *testing.T, sectionYAML string, variables map[string]interface{}, interactive bool, input string) ([]string, error) {
+	t.Helper()
+
+	var section TemplateSection
+	if err := yaml.Unmarshal([]byte(sectionYAML), &section); err != nil {
+		t.Fatal(err)
+	}
+	dp := newTestProcessor(false, variables, input)
+	err := dp.generateTable(section, interactive)
+	return dp.output, err
+}
+
+func TestGenerateTable_PrefilledRows(t *testing.T) {
+	lines, err := renderTable(t, `id: colors
+columns: ["Color Type", "Hex Code", "Usage"]
+rows:
+  - ["Primary", "{{primary_color}}", "Buttons | links"]
+  - category: "Status"
+    items:
+      - ["Error", "#f00", "Errors"]
+`, map[string]interface{}{"primary_color": "#0af"}, false, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{
+		"| Color Type | Hex Code | Usage |",
+		"|----------|----------|----------|",
+		`| Primary | #0af | Buttons \| links |`,
+		"| **Status** |  |  |",
+		"| Error | #f00 | Errors |",
+		"",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected table:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestGenerateTable_RowsFromVariable(t *testing.T) {
+	rows := []interface{}{
+		map[string]interface{}{"date": "2025-01-01", "Version": 1.0, "description": "Initial draft", "author": "PM"},
+		[]interface{}{"2025-02-01", "1.1", "Added NFRs", "PM"},
+	}
+
+	lines, err := renderTable(t, `id: changelog
+columns: [Date, Version, Description, Author]
+rows:
+  - ["{{date}}", "{{version}}", "{{description}}", "{{author}}"]
+`, map[string]interface{}{"changelog": rows}, false, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	table := strings.Join(lines, "\n")
+	if !strings.Contains(table, "| 2025-01-01 | 1 | Initial draft | PM |") || !strings.Contains(table, "| 2025-02-01 | 1.1 | Added NFRs | PM |") {
+		t.Errorf("Expected rows from the variable instead of the template rows:\n%s", table)
+	}
+
+	_, err = renderTable(t, "id: changelog\ncolumns: [Date, Version]\n",
+		map[string]interface{}{"changelog": []interface{}{[]interface{}{"only one"}}}, false, "")
+	if ErrorClassOf(err) != ErrorClassUserInput {
+		t.Errorf("Expected a user-input error for a short row, got %v", err)
+	}
+}
+
+func TestGenerateTable_InteractiveRowEntry(t *testing.T) {
+	input := "Login | too few\nLogin | Auth \\| SSO | High\n\n"
+	lines, err := renderTable(t, "id: risks\ncolumns: [Risk, Area, Impact]\n", nil, true, input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(lines) != 4 || lines[2] != `| Login | Auth \| SSO | High |` {
+		t.Errorf("Expected only the valid row, with its pipe escaped:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestGenerateTable_TemplateRowCellCount(t *testing.T) {
+	_, err := renderTable(t, "id: broken\ncolumns: [A, B]\nrows:\n  - [\"one\"]\n", nil, false, "")
+	if ErrorClassOf(err) != ErrorClassTemplate {
+		t.Errorf("Expected a template error for a prefilled row with the wrong cell count, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// goldenExtensions maps template output formats to golden file extensions
+var goldenExtensions = map[string]string{"": ".md", "markdown": ".md", "yaml": ".yml", "json": ".json"}
+
+// renderTemplateFile renders a template in yolo mode with no variables defined,
+// returning the output and the golden file extension for its format
+func renderTemplateFile(t *testing.T, path string) (string, string) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := parseTemplate(data)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %v", path, err)
+	}
+
+	dp := newTestProcessor(false, nil, "")
+	template, err = dp.scope.resolveTemplate(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dp.processTemplate(template, "yolo"); err != nil {
+		t.Fatalf("Error rendering %s: %v", path, err)
+	}
+	return strings.Join(dp.output, "\n") + "\n", goldenExtensions[template.Template.Output.Format]
+}
+
+func TestTemplates_Golden(t *testing.T) {
+	templates, err := filepath.Glob("../../bmad-core/templates/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) == 0 {
+		t.Fatal("No templates found in bmad-core/templates")
+	}
+
+	for _, path := range templates {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			got, ext := renderTemplateFile(t, path)
+			golden := filepath.Join("testdata", "golden", name+ext)
+
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file (run go test -run TestTemplates_Golden -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("Rendered %s differs from %s:\n%s", filepath.Base(path), golden, got)
+			}
+		})
+	}
+}
+
+func TestSectionHeading_FollowsDepth(t *testing.T) {
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{0, "## Goals"},
+		{1, "### Goals"},
+		{2, "#### Goals"},
+		{7, "###### Goals"},
+	}
+	for _, tt := range tests {
+		if got := sectionHeading("Goals", tt.depth); got != tt.want {
+			t.Errorf("sectionHeading(depth %d) = %q, want %q", tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestNumberedListItem_Prefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		item   string
+		want   string
+	}{
+		{"", "Login works", "1. Login works"},
+		{"FR", "Login works", "- FR1: Login works"},
+		{"FR", "FR1: Login works", "- FR1: Login works"},
+	}
+	for _, tt := range tests {
+		if got := numberedListItem(tt.prefix, 1, tt.item); got != tt.want {
+			t.Errorf("numberedListItem(%q, 1, %q) = %q, want %q", tt.prefix, tt.item, got, tt.want)
+		}
+	}
+}
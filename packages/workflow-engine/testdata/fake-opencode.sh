@@ -0,0 +1,19 @@
+#!/bin/sh
+# Fake opencode CLI used by the workflow engine tests.
+#
+# Echoes its arguments and anything piped on stdin on stdout, writes a line
+# to stderr and exits with $FAKE_OPENCODE_EXIT (default 0). When
+# $FAKE_OPENCODE_SLEEP is set the script sleeps that many seconds first so
+# cancellation can be exercised.
+
+if [ -n "$FAKE_OPENCODE_SLEEP" ]; then
+	sleep "$FAKE_OPENCODE_SLEEP"
+fi
+
+echo "fake-opencode $*"
+if [ ! -t 0 ]; then
+	cat
+fi
+echo "fake-opencode diagnostics" >&2
+
+exit "${FAKE_OPENCODE_EXIT:-0}"
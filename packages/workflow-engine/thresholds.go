@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// thresholdVariable is the workflow variable read as the default pass rate of checklist steps
+const thresholdVariable = "validation_threshold"
+
+// ChecklistThreshold gates a checklist step on its results
+type ChecklistThreshold struct {
+	PassRate    float64  `yaml:"pass_rate,omitempty"`           // minimum pass rate: a fraction (0.85) or a percentage (85)
+	NoBlockers  bool     `yaml:"no_blocker_failures,omitempty"` // fail when any blocker item fails
+	Blockers    []string `yaml:"blockers,omitempty"`            // item, category or section ids that are blockers
+	Remediation string   `yaml:"remediation,omitempty"`         // step to run instead of failing
+}
+
+// isBlocker reports whether an item is a blocker: marked [BLOCKER] in the
+// checklist, or listed in the threshold's blockers. A listed id covers the
+// items numbered under it, so "2.1" covers 2.1.3.
+func (t *ChecklistThreshold) isBlocker(item ChecklistItem) bool {
+	if item.Severity == "blocker" {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	for _, id := range t.Blockers {
+		if item.ID == id || strings.HasPrefix(item.ID, id+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// minimumPassRate returns the threshold's pass rate as a percentage
+func (t ChecklistThreshold) minimumPassRate() float64 {
+	if t.PassRate <= 1 {
+		return t.PassRate * 100
+	}
+	return t.PassRate
+}
+
+// check returns the reasons a checklist step's results do not meet the threshold
+func (t ChecklistThreshold) check(output *ChecklistStepOutput) []string {
+	var failures []string
+	if minimum := t.minimumPassRate(); output.PassRate < minimum {
+		failures = append(failures, fmt.Sprintf("pass rate %.1f%% is below %.1f%%", output.PassRate, minimum))
+	}
+	if t.NoBlockers && len(output.Blockers) > 0 {
+		failures = append(failures, fmt.Sprintf("blocker items failed: %s", strings.Join(output.Blockers, ", ")))
+	}
+	return failures
+}
+
+// checklistThreshold returns the threshold of a checklist step: its own
+// `threshold`, with the pass rate defaulting to the validation_threshold
+// variable. Steps with neither have no threshold.
+func (e *WorkflowEngine) checklistThreshold(step WorkflowStep) (*ChecklistThreshold, error) {
+	var threshold ChecklistThreshold
+	if step.Threshold != nil {
+		threshold = *step.Threshold
+	}
+
+	if threshold.PassRate == 0 {
+		if value, ok := e.variableScopeFor(step).Lookup(thresholdVariable); ok {
+			rate, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+			if err != nil || rate < 0 || rate > 100 {
+				return nil, NewStepError(ErrorClassWorkflowValidation, err, "invalid %s %q (expected a fraction or a percentage)", thresholdVariable, fmt.Sprint(value))
+			}
+			threshold.PassRate = rate
+		}
+	}
+
+	if step.Threshold == nil && threshold.PassRate == 0 {
+		return nil, nil
+	}
+	return &threshold, nil
+}
+
+// applyThreshold scores a checklist step's results against its threshold. A
+// step that misses the threshold fails, unless it names a remediation step to
+// route to, in which case it succeeds with `passed` false.
+func (e *WorkflowEngine) applyThreshold(step WorkflowStep, output *ChecklistStepOutput) error {
+	output.PassRate = checklistPassRate(output.Results)
+	output.Passed = true
+
+	threshold, err := e.checklistThreshold(step)
+	if err != nil {
+		return err
+	}
+	for _, item := range e.checklistProcessor.resultsWithStatus("fail") {
+		if threshold.isBlocker(item) {
+			output.Blockers = append(output.Blockers, item.ID)
+		}
+	}
+	if threshold == nil {
+		return nil
+	}
+
+	// Pending items would fail any threshold, though nothing judged them
+	if e.checklistProcessor.evaluatedItems() == 0 {
+		fmt.Printf("   ⚠️  Threshold skipped: no items were evaluated (yolo checklists need the step's agent)\n")
+		return nil
+	}
+
+	failures := threshold.check(output)
+	if len(failures) == 0 {
+		fmt.Printf("   🎯 Threshold met: %.1f%% passed (minimum %.1f%%)\n", output.PassRate, threshold.minimumPassRate())
+		return nil
+	}
+
+	output.Passed = false
+	fmt.Printf("   ❌ Threshold not met: %s\n", strings.Join(failures, "; "))
+	if threshold.Remediation != "" {
+		fmt.Printf("   🔀 Routing to remediation step: %s\n", threshold.Remediation)
+		return nil
+	}
+
+	return NewStepError(ErrorClassThreshold, nil, "checklist %q did not meet its threshold: %s", e.checklistProcessor.checklist.Name, strings.Join(failures, "; ")).
+		WithRemediation(fmt.Sprintf("Fix the failed items listed in %s, or set threshold.remediation to route to a remediation step", output.Report))
+}
+
+// evaluatedItems counts the items validation decided, leaving out those still
+// pending and those already checked in the checklist source
+func (cp *ChecklistProcessor) evaluatedItems() int {
+	count := 0
+	for _, section := range cp.checklist.Sections {
+		for _, item := range section.Items {
+			if checkedInSource(item) {
+				continue
+			}
+			if result, ok := cp.results[item.ID]; ok && result.Status != "pending" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// thresholdProblems checks the threshold of a step; index maps step ids to positions
+func thresholdProblems(steps []WorkflowStep, i int, index map[string]int) []stepProblem {
+	step := steps[i]
+	if step.Threshold == nil {
+		return nil
+	}
+
+	id := stepID(step, i)
+	var problems []stepProblem
+	if step.Checklist == "" {
+		problems = append(problems, stepProblem{i, "threshold",
+			fmt.Sprintf("step %d (%s): threshold is only used by checklist steps", i+1, id)})
+	}
+	if step.Threshold.PassRate < 0 || step.Threshold.PassRate > 100 {
+		problems = append(problems, stepProblem{i, "threshold",
+			fmt.Sprintf("step %d (%s): threshold pass_rate %v is not a fraction or a percentage", i+1, id, step.Threshold.PassRate)})
+	}
+
+	if remediation := step.Threshold.Remediation; remediation != "" {
+		target, exists := index[remediation]
+		switch {
+		case !exists:
+			problems = append(problems, stepProblem{i, "threshold",
+				fmt.Sprintf("step %d (%s): remediation step %q is unknown", i+1, id, remediation)})
+		case target <= i:
+			problems = append(problems, stepProblem{i, "threshold",
+				fmt.Sprintf("step %d (%s): remediation step %q must come after the checklist step", i+1, id, remediation)})
+		}
+	}
+	return problems
+}
+
+// remediatedSteps maps the index of each remediation step to the checklist step routing to it
+func remediatedSteps(steps []WorkflowStep, index map[string]int) map[int]int {
+	remediated := make(map[int]int)
+	for i, step := range steps {
+		if step.Threshold == nil || step.Threshold.Remediation == "" {
+			continue
+		}
+		if target, exists := index[step.Threshold.Remediation]; exists {
+			remediated[target] = i
+		}
+	}
+	return remediated
+}
+
+// routeRemediation marks the remediation step of a checklist step that met its
+// threshold as bypassed, so it only runs when the threshold is missed
+func (pe *ParallelExecutor) routeRemediation(steps []WorkflowStep, stepIndex int, bypassed map[int]string) {
+	step := steps[stepIndex]
+	if step.Threshold == nil || step.Threshold.Remediation == "" {
+		return
+	}
+
+	pe.mutex.RLock()
+	result := pe.stepResults[stepIndex]
+	pe.mutex.RUnlock()
+
+	output, ok := result.Output.(*ChecklistStepOutput)
+	if !ok || !result.Success || !output.Passed {
+		return
+	}
+
+	for i := range steps {
+		if stepID(steps[i], i) != step.Threshold.Remediation {
+			continue
+		}
+		if !pe.isRestored(i) {
+			bypassed[i] = fmt.Sprintf("step %d (%s) met its checklist threshold", stepIndex+1, stepID(step, stepIndex))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newThresholdEngine(variables map[string]interface{}, results ...ChecklistItem) (*WorkflowEngine, *ChecklistStepOutput) {
+	cp := &ChecklistProcessor{results: map[string]ChecklistItem{}}
+	section := ChecklistSection{ID: "1", Title: "Release"}
+	for _, item := range results {
+		section.Items = append(section.Items, item)
+		cp.results[item.ID] = item
+	}
+	cp.checklist = Checklist{Name: "Release Checklist", Sections: []ChecklistSection{section}}
+
+	engine := &WorkflowEngine{checklistProcessor: cp, workflowVariables: variables}
+	return engine, &ChecklistStepOutput{Report: "docs/checklist-report-1.md", Results: cp.results}
+}
+
+func TestApplyThreshold(t *testing.T) {
+	items := []ChecklistItem{
+		{ID: "1.1", Status: "pass", Severity: "medium"},
+		{ID: "1.2", Status: "pass", Severity: "medium"},
+		{ID: "1.3", Status: "n/a", Severity: "medium"},
+		{ID: "1.4", Status: "fail", Severity: "blocker"},
+	}
+
+	tests := []struct {
+		name      string
+		variables map[string]interface{}
+		threshold *ChecklistThreshold
+		passed    bool
+		err       string
+	}{
+		{"no threshold", nil, nil, true, ""},
+		{"workflow variable met", map[string]interface{}{"validation_threshold": 0.6}, nil, true, ""},
+		{"workflow variable missed", map[string]interface{}{"validation_threshold": 0.85}, nil, false, "pass rate 66.7% is below 85.0%"},
+		{"percentage", nil, &ChecklistThreshold{PassRate: 70}, false, "pass rate 66.7% is below 70.0%"},
+		{"step overrides variable", map[string]interface{}{"validation_threshold": "0.9"}, &ChecklistThreshold{PassRate: 0.5}, true, ""},
+		{"blocker failure", nil, &ChecklistThreshold{NoBlockers: true}, false, "blocker items failed: 1.4"},
+		{"remediation", nil, &ChecklistThreshold{PassRate: 0.9, Remediation: "fix"}, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, output := newThresholdEngine(tt.variables, items...)
+
+			err := engine.applyThreshold(WorkflowStep{Checklist: "release.md", Threshold: tt.threshold}, output)
+			if output.Passed != tt.passed || output.PassRate != 66.7 || len(output.Blockers) != 1 {
+				t.Errorf("Unexpected score: passed=%t pass_rate=%v blockers=%v", output.Passed, output.PassRate, output.Blockers)
+			}
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Unexpected error: %v", err)
+			case tt.err != "" && (ErrorClassOf(err) != ErrorClassThreshold || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("Expected a threshold error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	engine, output := newThresholdEngine(map[string]interface{}{"validation_threshold": "high"}, items...)
+	if err := engine.applyThreshold(WorkflowStep{Checklist: "release.md"}, output); ErrorClassOf(err) != ErrorClassWorkflowValidation {
+		t.Errorf("Expected an invalid validation_threshold to be a workflow-validation error, got %v", err)
+	}
+}
+
+func TestApplyThreshold_ConfiguredBlockers(t *testing.T) {
+	cp := &ChecklistProcessor{results: map[string]ChecklistItem{}}
+	if err := cp.loadChecklist("../../bmad-core/checklists/pm-checklist.md"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, section := range cp.checklist.Sections {
+		for _, item := range section.Items {
+			item.Status = "pass"
+			if item.ID == "2.1.3" || item.ID == "5.1.1" {
+				item.Status = "fail"
+			}
+			cp.results[item.ID] = item
+		}
+	}
+
+	engine := &WorkflowEngine{checklistProcessor: cp}
+	output := &ChecklistStepOutput{Report: "docs/checklist-report-1.md", Results: cp.results}
+	threshold := &ChecklistThreshold{NoBlockers: true, Blockers: []string{"1.1", "2.1", "4.1", "6.3"}}
+	err := engine.applyThreshold(WorkflowStep{Checklist: "pm-checklist.md", Threshold: threshold}, output)
+
+	// 2.1 Core Functionality is listed as a blocker, 5.1 Performance Requirements is not
+	if ErrorClassOf(err) != ErrorClassThreshold || !strings.Contains(err.Error(), "blocker items failed: 2.1.3") {
+		t.Errorf("Expected the failed blocker item to miss the threshold, got %v", err)
+	}
+	if fmt.Sprint(output.Blockers) != "[2.1.3]" {
+		t.Errorf("Expected only 2.1.3 as a blocker failure, got %v", output.Blockers)
+	}
+}
+
+func TestExecuteChecklistTask_ThresholdWithoutAgent(t *testing.T) {
+	dir := t.TempDir()
+	checklistPath := filepath.Join(dir, "release-checklist.md")
+	if err := ioutil.WriteFile(checklistPath, []byte(evaluationChecklist), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &WorkflowEngine{
+		checklistProcessor: &ChecklistProcessor{results: map[string]ChecklistItem{}},
+		workflowVariables:  map[string]interface{}{"validation_threshold": 0.85}, // as in complex-multi-step.yaml
+		paths:              NewPathResolver(checklistPath, PathConfig{}, nil, dir),
+	}
+
+	// Without an agent nothing evaluates the unchecked items, so the threshold
+	// is skipped instead of failing on the pending ones
+	output, err := engine.executeChecklistTask(WorkflowStep{Checklist: checklistPath, Mode: "yolo"}, 1)
+	if err != nil {
+		t.Fatalf("Expected the threshold to be skipped, got %v", err)
+	}
+	if output.PassRate != 25 || !output.Passed {
+		t.Errorf("Expected a 25%% pass rate with the threshold skipped, got %.1f%% (passed=%t)", output.PassRate, output.Passed)
+	}
+	for _, id := range []string{"1.1", "1.2", "2.1"} {
+		if output.Results[id].Status != "pending" {
+			t.Errorf("Expected %s to stay pending, got %+v", id, output.Results[id])
+		}
+	}
+
+	// With nothing checked in the source either, the 0% pass rate does not fail the step
+	unchecked := strings.Replace(evaluationChecklist, "- [x]", "- [ ]", 1)
+	if err := ioutil.WriteFile(checklistPath, []byte(unchecked), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err = engine.executeChecklistTask(WorkflowStep{Checklist: checklistPath, Mode: "yolo"}, 2)
+	if err != nil || output.PassRate != 0 || !output.Passed {
+		t.Errorf("Expected a skipped threshold at 0%%, got %.1f%% (passed=%t) and %v", output.PassRate, output.Passed, err)
+	}
+
+	// Once an item is evaluated, the threshold applies again
+	engine.checklistProcessor.results["1.1"] = ChecklistItem{ID: "1.1", Status: "fail", Severity: "medium"}
+	if err := engine.applyThreshold(WorkflowStep{Checklist: checklistPath}, output); ErrorClassOf(err) != ErrorClassThreshold {
+		t.Errorf("Expected an evaluated checklist to miss the threshold, got %v", err)
+	}
+}
+
+// remediationEngine runs a checklist step with a fixed outcome and records which steps ran
+type remediationEngine struct {
+	passed bool
+	mu     sync.Mutex
+	ran    []string
+}
+
+func (r *remediationEngine) executeStep(step WorkflowStep, stepNum int) (interface{}, error) {
+	r.mu.Lock()
+	r.ran = append(r.ran, step.ID)
+	r.mu.Unlock()
+
+	if step.Checklist != "" {
+		return &ChecklistStepOutput{Passed: r.passed}, nil
+	}
+	return nil, nil
+}
+
+func TestRemediationRouting(t *testing.T) {
+	steps := []WorkflowStep{
+		{ID: "validate", Checklist: "release.md", Threshold: &ChecklistThreshold{PassRate: 0.85, Remediation: "fix"}},
+		{ID: "fix"},
+		{ID: "publish", DependsOn: []string{"fix"}},
+	}
+
+	for _, enableParallel := range []bool{true, false} {
+		for _, passed := range []bool{true, false} {
+			t.Run(fmt.Sprintf("parallel=%t/passed=%t", enableParallel, passed), func(t *testing.T) {
+				config := DefaultParallelConfig()
+				config.EnableParallel = enableParallel
+				executor := NewParallelExecutor(config)
+				defer executor.Cleanup()
+
+				engine := &remediationEngine{passed: passed}
+				if err := executor.ExecuteParallel(engine, steps); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				want := "validate,fix,publish"
+				if passed {
+					want = "validate,publish"
+				}
+				if got := strings.Join(engine.ran, ","); got != want {
+					t.Errorf("Expected steps %s to run, got %s", want, got)
+				}
+
+				fix := executor.GetResults()[1]
+				if fix.Skipped != passed || !fix.Success {
+					t.Errorf("Unexpected remediation result: %+v", fix)
+				}
+			})
+		}
+	}
+}
+
+func TestThresholdProblems(t *testing.T) {
+	steps := []WorkflowStep{
+		{ID: "fix"},
+		{ID: "validate", Checklist: "release.md", Threshold: &ChecklistThreshold{PassRate: 120, Remediation: "fix"}},
+		{ID: "draft", Threshold: &ChecklistThreshold{Remediation: "missing"}},
+	}
+
+	var messages []string
+	for _, problem := range stepReferenceProblems(steps) {
+		messages = append(messages, problem.Message)
+	}
+	got := strings.Join(messages, "\n")
+
+	for _, want := range []string{
+		`step 2 (validate): threshold pass_rate 120 is not a fraction or a percentage`,
+		`step 2 (validate): remediation step "fix" must come after the checklist step`,
+		`step 3 (draft): threshold is only used by checklist steps`,
+		`step 3 (draft): remediation step "missing" is unknown`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+}
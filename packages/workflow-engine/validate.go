@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validModes are the accepted values of a step's mode
+var validModes = map[string]bool{"": true, "interactive": true, "yolo": true}
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)$`)
+
+// ValidationProblem is a single problem found while linting a workflow file
+type ValidationProblem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Format renders the problem as file:line:column: message
+func (p ValidationProblem) Format(file string) string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", file, p.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", file, p.Line, p.Column, p.Message)
+}
+
+// ResolvedPath records where a referenced file was found; Step is -1 for workflow-level files
+type ResolvedPath struct {
+	Step     int
+	Field    string
+	Path     string
+	Resolved string
+}
+
+// ValidationReport is the result of linting a workflow file
+type ValidationReport struct {
+	Problems []ValidationProblem
+	Resolved []ResolvedPath
+}
+
+// workflowLinter collects problems for a workflow file and maps them to YAML positions
+type workflowLinter struct {
+	path   string
+	dir    string
+	root   *yaml.Node
+	paths  *PathResolver
+	report ValidationReport
+}
+
+// validateWorkflowFile lints a workflow file and returns every problem found.
+// The error is only set when the file cannot be read at all.
+func validateWorkflowFile(path string, opts runOptions) (*ValidationReport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, NewStepError(ErrorClassFilesystem, err, "error reading workflow file")
+	}
+
+	l := &workflowLinter{path: path, dir: filepath.Dir(path)}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		l.addYAMLError(err)
+		return &l.report, nil
+	}
+	if len(document.Content) > 0 {
+		l.root = document.Content[0]
+	}
+
+	// Unknown keys are usually typos that would otherwise be dropped silently
+	var workflow Workflow
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&workflow); err != nil {
+		l.addYAMLError(err)
+		workflow = Workflow{}
+		if err := yaml.Unmarshal(data, &workflow); err != nil {
+			return &l.report, nil
+		}
+	}
+
+	l.paths = NewPathResolver(path, workflow.Paths, opts.searchRoots, opts.projectRoot)
+	l.lintWorkflow(&workflow)
+
+	sort.SliceStable(l.report.Problems, func(i, j int) bool {
+		return l.report.Problems[i].Line < l.report.Problems[j].Line
+	})
+	return &l.report, nil
+}
+
+func (l *workflowLinter) lintWorkflow(workflow *Workflow) {
+	if len(workflow.Steps) == 0 {
+		l.add(l.key(l.root, "steps"), "workflow has no steps")
+		return
+	}
+
+	if err := validateFailurePolicy(workflow.Parallel.FailurePolicy); err != nil {
+		l.add(l.value(l.value(l.root, "parallel"), "failure_policy"), err.Error())
+	}
+
+	if workflow.Answers != "" {
+		l.checkFile(-1, l.root, "answers", "workflow", workflow.Answers)
+	}
+
+	agents := l.knownAgents()
+
+	for i, step := range workflow.Steps {
+		node := l.step(i)
+		label := fmt.Sprintf("step %d (%s)", i+1, stepID(step, i))
+
+		if !validModes[step.Mode] {
+			l.add(l.value(node, "mode"), fmt.Sprintf("%s: unknown mode %q (expected interactive or yolo)", label, step.Mode))
+		}
+
+		switch {
+		case step.Agent == "" && stepKind(step) == "agent":
+			l.add(node, fmt.Sprintf("%s: no agent set", label))
+		case step.Agent != "" && agents != nil && !agents[step.Agent]:
+			l.add(l.value(node, "agent"), fmt.Sprintf("%s: unknown agent %q (known: %s)", label, step.Agent, joinKeys(agents)))
+		}
+
+		if step.Template != "" {
+			l.checkFile(i, node, "template", label, step.Template)
+		}
+		if step.Checklist != "" {
+			l.checkFile(i, node, "checklist", label, step.Checklist)
+		}
+		if step.Answers != "" {
+			l.checkFile(i, node, "answers", label, step.Answers)
+		}
+	}
+
+	references := stepReferenceProblems(workflow.Steps)
+	for _, problem := range references {
+		field := l.key(l.step(problem.Step), problem.Field)
+		if field == nil {
+			field = l.step(problem.Step)
+		}
+		l.add(field, problem.Message)
+	}
+
+	// Cycles can only be detected once every reference resolves
+	if len(references) == 0 {
+		executor := NewParallelExecutor(workflow.Parallel.withDefaults())
+		defer executor.Cleanup()
+
+		graph := executor.buildGraph(workflow.Steps)
+		if node := executor.findCycle(graph); node >= 0 {
+			l.add(l.step(node), fmt.Sprintf("step %d (%s): circular dependency", node+1, graph.Steps[node].ID))
+		}
+	}
+}
+
+// checkFile resolves a template or checklist file, reporting it when it cannot be found
+func (l *workflowLinter) checkFile(stepIndex int, step *yaml.Node, field, label, path string) {
+	if strings.Contains(path, "{{") {
+		return // resolved from variables at run time
+	}
+
+	resolved, err := l.paths.Resolve(path)
+	if err != nil {
+		l.add(l.value(step, field), fmt.Sprintf("%s: %s %v", label, field, err))
+		return
+	}
+
+	l.report.Resolved = append(l.report.Resolved, ResolvedPath{Step: stepIndex, Field: field, Path: path, Resolved: resolved})
+}
+
+// knownAgents returns the agent names defined in the nearest bmad-core/agents
+// directory, or nil when none can be found
+func (l *workflowLinter) knownAgents() map[string]bool {
+	dir := findAgentsDir(l.dir)
+	if dir == "" {
+		return nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	agents := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			agents[strings.TrimSuffix(entry.Name(), ".md")] = true
+		}
+	}
+	return agents
+}
+
+// findAgentsDir returns the agents directory of the nearest bmad-core, looking
+// up from dir and then from the working directory
+func findAgentsDir(dir string) string {
+	for _, start := range []string{dir, "."} {
+		if core := findBmadCoreDir(start); core != "" {
+			agents := filepath.Join(core, "agents")
+			if info, err := os.Stat(agents); err == nil && info.IsDir() {
+				return agents
+			}
+		}
+	}
+	return ""
+}
+
+// step returns the mapping node of the i-th step, or nil
+func (l *workflowLinter) step(i int) *yaml.Node {
+	steps := l.value(l.root, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode || i < 0 || i >= len(steps.Content) {
+		return nil
+	}
+	return steps.Content[i]
+}
+
+// key returns the key node for a field of a mapping node, or nil
+func (l *workflowLinter) key(node *yaml.Node, field string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == field {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// value returns the value node for a field of a mapping node, falling back to
+// the mapping itself so problems still carry a position
+func (l *workflowLinter) value(node *yaml.Node, field string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return node
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == field {
+			return node.Content[i+1]
+		}
+	}
+	return node
+}
+
+// add records a problem at the position of node, if known
+func (l *workflowLinter) add(node *yaml.Node, message string) {
+	problem := ValidationProblem{Message: message}
+	if node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+	l.report.Problems = append(l.report.Problems, problem)
+}
+
+// addYAMLError records yaml.v3 syntax and type errors with their line numbers
+func (l *workflowLinter) addYAMLError(err error) {
+	messages := []string{err.Error()}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	for _, message := range messages {
+		problem := ValidationProblem{Message: strings.TrimPrefix(message, "yaml: ")}
+		if match := yamlLinePattern.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Column = firstColumnOnLine(l.root, problem.Line)
+			problem.Message = match[2]
+		}
+		l.report.Problems = append(l.report.Problems, problem)
+	}
+}
+
+// firstColumnOnLine returns the column of the first scalar starting on line, or 1
+func firstColumnOnLine(node *yaml.Node, line int) int {
+	if found := firstScalarOnLine(node, line); found != nil {
+		return found.Column
+	}
+	return 1
+}
+
+func firstScalarOnLine(node *yaml.Node, line int) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode && node.Line == line {
+		return node
+	}
+	for _, child := range node.Content {
+		if found := firstScalarOnLine(child, line); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// joinKeys returns the sorted keys of a set joined with commas
+func joinKeys(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// runValidate lints a workflow file and exits non-zero when problems are found
+func runValidate(workflowFile string, opts runOptions) {
+	fmt.Printf("\n🔍 Validating workflow: %s\n", workflowFile)
+
+	report, err := validateWorkflowFile(workflowFile, opts)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(exitCodeForError(err))
+	}
+
+	for _, resolved := range report.Resolved {
+		owner := "Workflow"
+		if resolved.Step >= 0 {
+			owner = fmt.Sprintf("Step %d", resolved.Step+1)
+		}
+		fmt.Printf("   📂 %s %s: %s → %s\n", owner, resolved.Field, resolved.Path, resolved.Resolved)
+	}
+
+	problems := report.Problems
+	if len(problems) == 0 {
+		fmt.Printf("✅ Workflow is valid\n")
+		return
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("   ❌ %s\n", problem.Format(workflowFile))
+	}
+	fmt.Printf("\n❌ %d problem(s) found\n", len(problems))
+	os.Exit(exitInvalid)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLintFixture creates a project with bmad-core/agents and a workflow under workflows/
+func writeLintFixture(t *testing.T, workflow string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range []string{"bmad-core/agents", "bmad-core/templates", "workflows"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"bmad-core/agents/pm.md", "bmad-core/agents/architect.md", "bmad-core/templates/prd-tmpl.yaml"} {
+		if err := ioutil.WriteFile(filepath.Join(root, file), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return writeTestWorkflow(t, filepath.Join(root, "workflows"), workflow)
+}
+
+func TestValidateWorkflowFile_Valid(t *testing.T) {
+	path := writeLintFixture(t, `name: ok
+steps:
+  - id: prd
+    agent: pm
+    template: ../bmad-core/templates/prd-tmpl.yaml
+    mode: yolo
+  - id: design
+    agent: architect
+    depends_on: [prd]
+`)
+
+	report, err := validateWorkflowFile(path, runOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	problems := report.Problems
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+}
+
+func TestValidateWorkflowFile_ReportsProblemsWithPositions(t *testing.T) {
+	path := writeLintFixture(t, `name: broken
+parallel:
+  failure_policy: sometimes
+steps:
+  - id: prd
+    agent: pmm
+    tempalte: prd.yaml
+    mode: fast
+  - id: check
+    agent: pm
+    checklist: missing-checklist.md
+    depends_on: [nope]
+`)
+
+	report, err := validateWorkflowFile(path, runOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	problems := report.Problems
+
+	expected := []struct {
+		line    int
+		column  int
+		message string
+	}{
+		{3, 19, `unknown failure_policy "sometimes"`},
+		{6, 12, `unknown agent "pmm"`},
+		{7, 5, "field tempalte not found"},
+		{8, 11, `unknown mode "fast"`},
+		{11, 16, `checklist file "missing-checklist.md" not found`},
+		{12, 5, `depends on unknown step id "nope"`},
+	}
+
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Line != want.line || got.Column != want.column || !strings.Contains(got.Message, want.message) {
+			t.Errorf("Problem %d: expected %d:%d %q, got %s", i, want.line, want.column, want.message, got.Format("wf.yaml"))
+		}
+	}
+}
+
+func TestValidateWorkflowFile_Cycle(t *testing.T) {
+	path := writeLintFixture(t, `name: cycle
+steps:
+  - id: a
+    agent: pm
+    depends_on: [b]
+  - id: b
+    agent: pm
+    depends_on: [a]
+`)
+
+	report, err := validateWorkflowFile(path, runOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	problems := report.Problems
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "circular dependency") || problems[0].Line == 0 {
+		t.Errorf("Expected one positioned cycle problem, got %+v", problems)
+	}
+}
+
+func TestValidateWorkflowFile_SyntaxError(t *testing.T) {
+	path := writeLintFixture(t, "name: bad\nsteps:\n  - agent: pm\n   task: x\n")
+
+	report, err := validateWorkflowFile(path, runOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	problems := report.Problems
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("Expected one positioned syntax problem, got %+v", problems)
+	}
+}
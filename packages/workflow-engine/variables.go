@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// variablePattern matches {{name}} placeholders; names may contain dots for nested lookups
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.\-]*)\s*\}\}`)
+
+// VariableScope resolves {{var}} placeholders from layered variable maps.
+// Layers are searched in order, so earlier layers take precedence; the
+// process environment is consulted last.
+type VariableScope struct {
+	layers  []map[string]interface{}
+	outputs *StepOutputs
+	env     func(string) (string, bool)
+	strict  bool
+	// deferred names are set while rendering (e.g. by choices) and are left
+	// in place, even in strict mode, until then
+	deferred map[string]bool
+}
+
+// NewVariableScope creates a scope from layers ordered highest precedence first
+func NewVariableScope(strict bool, layers ...map[string]interface{}) *VariableScope {
+	return &VariableScope{
+		layers: layers,
+		env:    os.LookupEnv,
+		strict: strict,
+	}
+}
+
+// with returns a child scope in which layer takes precedence over s
+func (s *VariableScope) with(layer map[string]interface{}) *VariableScope {
+	child := *s
+	child.layers = append([]map[string]interface{}{layer}, s.layers...)
+	return &child
+}
+
+// deferring returns a child scope that leaves the named variables for later
+func (s *VariableScope) deferring(names []string) *VariableScope {
+	if len(names) == 0 {
+		return s
+	}
+	child := *s
+	child.deferred = make(map[string]bool, len(s.deferred)+len(names))
+	for name := range s.deferred {
+		child.deferred[name] = true
+	}
+	for _, name := range names {
+		child.deferred[name] = true
+	}
+	return &child
+}
+
+// Lookup returns the value of a variable from the highest-precedence layer defining it
+func (s *VariableScope) Lookup(name string) (interface{}, bool) {
+	if strings.HasPrefix(name, stepOutputPrefix) {
+		if s.outputs == nil {
+			return nil, false
+		}
+		return s.outputs.Lookup(name)
+	}
+
+	for _, layer := range s.layers {
+		if value, ok := layer[name]; ok {
+			return value, true
+		}
+	}
+
+	if s.env != nil {
+		if value, ok := s.env(name); ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// flatten returns the variables visible in the scope, excluding the
+// environment and step outputs
+func (s *VariableScope) flatten() map[string]interface{} {
+	variables := map[string]interface{}{}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		for name, value := range s.layers[i] {
+			variables[name] = value
+		}
+	}
+	return variables
+}
+
+// Interpolate replaces every {{var}} placeholder in text. Undefined variables
+// are an error in strict mode and are otherwise left in place.
+func (s *VariableScope) Interpolate(text string) (string, error) {
+	var undefined []string
+
+	result := variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := variablePattern.FindStringSubmatch(placeholder)[1]
+		value, ok := s.Lookup(name)
+		if !ok {
+			if !s.deferred[name] {
+				undefined = append(undefined, name)
+			}
+			return placeholder
+		}
+		return fmt.Sprintf("%v", value)
+	})
+
+	if s.strict && len(undefined) > 0 {
+		return text, NewStepError(ErrorClassWorkflowValidation, nil, "undefined variable(s) %s in %q",
+			strings.Join(undefined, ", "), text).
+			WithRemediation("Define the variable under `variables`, on the step, or pass it with --var name=value")
+	}
+
+	return result, nil
+}
+
+// interpolateFields interpolates each of the given strings in place
+func (s *VariableScope) interpolateFields(fields ...*string) error {
+	for _, field := range fields {
+		value, err := s.Interpolate(*field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+	return nil
+}
+
+// resolveStep returns a copy of step with its prompt, task and file paths interpolated
+func (s *VariableScope) resolveStep(step WorkflowStep) (WorkflowStep, error) {
+	resolved := step
+	err := s.interpolateFields(&resolved.Prompt, &resolved.Task, &resolved.Template, &resolved.Checklist)
+	return resolved, err
+}
+
+// resolveVariables returns a copy of variables with its string values interpolated
+func (s *VariableScope) resolveVariables(variables map[string]interface{}) (map[string]interface{}, error) {
+	if variables == nil {
+		return nil, nil
+	}
+
+	resolved := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		if text, ok := value.(string); ok {
+			interpolated, err := s.Interpolate(text)
+			if err != nil {
+				return nil, err
+			}
+			value = interpolated
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// resolveTemplate returns a copy of template with its title, output filename,
+// section titles and section instructions interpolated
+func (s *VariableScope) resolveTemplate(template Template) (Template, error) {
+	s = s.deferring(choiceVariables(template.Sections))
+	resolved := template
+	if err := s.interpolateFields(&resolved.Template.Output.Title, &resolved.Template.Output.Filename); err != nil {
+		return template, err
+	}
+
+	sections, err := s.resolveSections(template.Sections)
+	if err != nil {
+		return template, err
+	}
+	resolved.Sections = sections
+
+	return resolved, nil
+}
+
+func (s *VariableScope) resolveSections(sections []TemplateSection) ([]TemplateSection, error) {
+	if sections == nil {
+		return nil, nil
+	}
+
+	resolved := make([]TemplateSection, len(sections))
+	for i, section := range sections {
+		// Repeatable sections are interpolated per instance, with their instance variables
+		if section.Repeatable {
+			resolved[i] = section
+			continue
+		}
+
+		if err := s.interpolateFields(&section.Title, &section.Instruction); err != nil {
+			return nil, err
+		}
+
+		children, err := s.resolveSections(section.Sections)
+		if err != nil {
+			return nil, err
+		}
+		section.Sections = children
+		resolved[i] = section
+	}
+
+	return resolved, nil
+}
+
+// variableScopeFor builds the scope for a step: CLI > step > workflow > environment,
+// with steps.<id>.outputs.<name> resolved from the run's step outputs
+func (e *WorkflowEngine) variableScopeFor(step WorkflowStep) *VariableScope {
+	scope := NewVariableScope(e.strictVariables, e.cliVariables, step.Variables, e.workflowVariables)
+	if e.parallelExecutor != nil {
+		scope.outputs = e.parallelExecutor.outputs
+	}
+	return scope
+}
+
+// resolveStep interpolates a step's variables, then its prompt, task and file
+// paths, which see the resolved variable values
+func (e *WorkflowEngine) resolveStep(step WorkflowStep) (WorkflowStep, error) {
+	variables, err := e.variableScopeFor(step).resolveVariables(step.Variables)
+	if err != nil {
+		return step, err
+	}
+	step.Variables = variables
+	return e.variableScopeFor(step).resolveStep(step)
+}
+
+// parseVariableAssignment parses a --var argument of the form name=value
+func parseVariableAssignment(arg string) (string, string, error) {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", NewStepError(ErrorClassUserInput, nil, "invalid --var %q (expected name=value)", arg)
+	}
+	return strings.TrimSpace(parts[0]), parts[1], nil
+}
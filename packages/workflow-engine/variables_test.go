@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVariableScope_Precedence(t *testing.T) {
+	cli := map[string]interface{}{"project_name": "FromCLI"}
+	step := map[string]interface{}{"project_name": "FromStep", "owner": "step-owner"}
+	workflow := map[string]interface{}{"project_name": "FromWorkflow", "owner": "wf-owner", "version": 2}
+
+	scope := NewVariableScope(false, cli, step, workflow)
+	scope.env = func(name string) (string, bool) {
+		if name == "TEAM" {
+			return "platform", true
+		}
+		return "", false
+	}
+
+	got, err := scope.Interpolate("{{project_name}} by {{owner}} v{{ version }} ({{TEAM}})")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := "FromCLI by step-owner v2 (platform)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestVariableScope_UndefinedVariables(t *testing.T) {
+	lenient := NewVariableScope(false)
+	lenient.env = nil
+
+	got, err := lenient.Interpolate("{{missing}} PRD")
+	if err != nil {
+		t.Fatalf("Non-strict mode should not fail: %v", err)
+	}
+	if got != "{{missing}} PRD" {
+		t.Errorf("Expected undefined placeholder left in place, got %q", got)
+	}
+
+	strict := NewVariableScope(true)
+	strict.env = nil
+
+	_, err = strict.Interpolate("{{missing}} PRD")
+	if err == nil {
+		t.Fatal("Strict mode should fail on undefined variables")
+	}
+	if ErrorClassOf(err) != ErrorClassWorkflowValidation || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected a workflow-validation error naming the variable, got %v", err)
+	}
+}
+
+func TestVariableScope_ResolveTemplate(t *testing.T) {
+	var template Template
+	template.Template.Output.Title = "{{project_name}} Product Requirements Document (PRD)"
+	template.Template.Output.Filename = "docs/{{project_name}}-prd.md"
+	template.Sections = []TemplateSection{
+		{
+			Title:       "Goals for {{project_name}}",
+			Instruction: "Describe goals of {{project_name}}",
+			Sections:    []TemplateSection{{Title: "{{project_name}} Background"}},
+		},
+	}
+
+	scope := NewVariableScope(true, map[string]interface{}{"project_name": "Acme"})
+	resolved, err := scope.resolveTemplate(template)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resolved.Template.Output.Title != "Acme Product Requirements Document (PRD)" {
+		t.Errorf("Title not interpolated: %q", resolved.Template.Output.Title)
+	}
+	if resolved.Template.Output.Filename != "docs/Acme-prd.md" {
+		t.Errorf("Filename not interpolated: %q", resolved.Template.Output.Filename)
+	}
+	if resolved.Sections[0].Instruction != "Describe goals of Acme" || resolved.Sections[0].Sections[0].Title != "Acme Background" {
+		t.Errorf("Sections not interpolated: %+v", resolved.Sections)
+	}
+	if template.Sections[0].Title != "Goals for {{project_name}}" {
+		t.Error("resolveTemplate should not modify the original template")
+	}
+}
+
+func TestExecuteTemplateTask_InterpolatesVariables(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "prd-tmpl.yaml")
+	templateYAML := `template:
+  id: prd
+  name: PRD
+  version: "1.0"
+  output:
+    format: markdown
+    filename: "{{output_dir}}/{{project_name}}-prd.md"
+    title: "{{project_name}} Product Requirements Document (PRD)"
+sections:
+  - id: goals
+    title: Goals
+    type: paragraphs
+`
+	if err := ioutil.WriteFile(templatePath, []byte(templateYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &WorkflowEngine{
+		processor:         newTestProcessor(false, nil, ""),
+		cliVariables:      map[string]interface{}{"project_name": "Acme"},
+		workflowVariables: map[string]interface{}{"project_name": "Default", "output_dir": dir},
+		paths:             NewPathResolver(templatePath, PathConfig{}, nil, dir),
+	}
+
+	step := WorkflowStep{Template: templatePath, Mode: "yolo"}
+	output, err := engine.executeTemplateTask(step, 1)
+	if err != nil {
+		t.Fatalf("Template task failed: %v", err)
+	}
+
+	if output.File != filepath.Join(dir, "Acme-prd.md") {
+		t.Errorf("Unexpected output file %q", output.File)
+	}
+	if output.Document[0] != "# Acme Product Requirements Document (PRD)" {
+		t.Errorf("Unexpected document title %q", output.Document[0])
+	}
+}
+
+func TestExecuteTemplateTask_CheckpointsAnswers(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "story-tmpl.yaml")
+	templateYAML := `template:
+  id: story
+  name: Story
+  version: "1.0"
+  output:
+    format: markdown
+    filename: story.md
+    title: Story
+sections:
+  - id: status
+    title: Status
+    type: choice
+    choices: [Draft, Approved]
+  - id: owner
+    title: Owner
+    template: "{{owner}}"
+`
+	if err := ioutil.WriteFile(templatePath, []byte(templateYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpointed := map[string]map[string]interface{}{}
+	engine := &WorkflowEngine{
+		processor: newTestProcessor(false, nil, ""),
+		paths:     NewPathResolver(templatePath, PathConfig{}, nil, dir),
+		onAnswers: func(step string, answers map[string]interface{}) {
+			copied := map[string]interface{}{}
+			for name, value := range answers {
+				copied[name] = value
+			}
+			checkpointed[step] = copied
+		},
+	}
+	run := func(id, input string) (string, error) {
+		engine.processor.reader = newTestProcessor(false, nil, input).reader
+		output, err := engine.executeTemplateTask(WorkflowStep{ID: id, Template: templatePath, Mode: "interactive"}, 1)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(output.Document, "\n"), nil
+	}
+
+	// The input ends after the first answer, as when the run is interrupted
+	if _, err := run("story", "2\n"); err == nil {
+		t.Fatal("Expected the template task to fail without an owner")
+	}
+	want := map[string]interface{}{"status": "Approved"}
+	if got := checkpointed["story"]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected answers %v checkpointed for the step, got %v", want, checkpointed)
+	}
+
+	// Resuming the step only asks for what is still missing; other steps ask again
+	engine.resumedAnswers = checkpointed
+	document, err := run("story", "Ada\n")
+	if err != nil {
+		t.Fatalf("Template task failed: %v", err)
+	}
+	if !strings.Contains(document, "Approved") || !strings.Contains(document, "Ada") {
+		t.Errorf("Expected the checkpointed and new answers in the document:\n%s", document)
+	}
+
+	document, err = run("prd", "1\nGrace\n")
+	if err != nil {
+		t.Fatalf("Template task failed: %v", err)
+	}
+	if !strings.Contains(document, "Draft") || !strings.Contains(document, "Grace") {
+		t.Errorf("Expected another step to ask for its own answers:\n%s", document)
+	}
+	if _, reused := engine.variableScopeFor(WorkflowStep{ID: "prd"}).Lookup("owner"); reused {
+		t.Error("Expected answers to stay out of other steps' variables")
+	}
+}
+
+func TestParseRunOptions(t *testing.T) {
+	opts, err := parseRunOptions([]string{"--var", "project_name=Acme", "workflow.yaml", "--strict", "--var=owner=a=b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(opts.args) != 1 || opts.args[0] != "workflow.yaml" {
+		t.Errorf("Expected one positional argument, got %v", opts.args)
+	}
+	if !opts.strict {
+		t.Error("Expected --strict after the workflow file to be parsed")
+	}
+	if opts.variables["project_name"] != "Acme" || opts.variables["owner"] != "a=b" {
+		t.Errorf("Unexpected variables: %v", opts.variables)
+	}
+
+	if _, err := parseRunOptions([]string{"--var", "novalue", "workflow.yaml"}); err == nil {
+		t.Error("Expected an error for --var without '='")
+	}
+}